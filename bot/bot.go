@@ -0,0 +1,130 @@
+package bot
+
+import (
+	"SpaceBumper/core"
+)
+
+// Tuning of the StarBot
+const (
+	replanInterval = 15   // ticks between two path searches
+	brakeFactor    = 0.08 // counter steering of the velocity (higher = slower, but safer)
+)
+
+// interface check: core.Controller
+var _ core.Controller = (*StarBot)(nil)
+
+// StarBot is a simple built-in bot (see core.Controller).
+// It drives on the shortest path to the nearest star
+// and avoids the void, blocks and anti-stars.
+// (see NewStarBot)
+type StarBot struct {
+	path   []*core.Cell // planned path (next cell first)
+	replan int          // ticks until the next path search
+}
+
+// NewStarBot creates a new bot. Each ship needs its own bot.
+func NewStarBot() *StarBot {
+	return &StarBot{}
+}
+
+// Control steers the ship to the nearest star (see core.Controller).
+func (b *StarBot) Control(m *core.WorldMap, s *core.Ship) {
+	pos := s.Position()
+	here := m.CellByVector(pos)
+
+	// plan path
+	b.replan--
+	if b.replan <= 0 || len(b.path) == 0 {
+		b.path = path(m, here, true)
+		if b.path == nil {
+			b.path = path(m, here, false) // stars behind anti-stars
+		}
+		b.replan = replanInterval
+		s.SetDrawing(drawPath(pos, b.path))
+	}
+
+	// skip reached cells
+	for len(b.path) > 0 && b.path[0] == here {
+		b.path = b.path[1:]
+	}
+
+	// no star: stop
+	vel := s.Velocity()
+	if len(b.path) == 0 {
+		vel.Multi(-brakeFactor)
+		s.Move(vel)
+		return
+	}
+
+	// steer to the next cell and counter steer the velocity
+	target := b.path[0].Center()
+	acc := core.NewVector((target.X()-pos.X())/core.CellSize, (target.Y()-pos.Y())/core.CellSize)
+	acc.Add(vel, -brakeFactor)
+	s.Move(acc)
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+// path returns the shortest path from the start cell to the nearest star (without the start cell).
+// If avoid is set, anti-stars and crumble cells are avoided (see passable).
+// Returns nil if no star is reachable.
+func path(m *core.WorldMap, start *core.Cell, avoid bool) []*core.Cell {
+	prev := map[*core.Cell]*core.Cell{start: nil}
+	queue := []*core.Cell{start}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+
+		// star found
+		if c.Type() == core.Star && c != start {
+			p := make([]*core.Cell, 0, 16)
+			for ; c != start; c = prev[c] {
+				p = append([]*core.Cell{c}, p...)
+			}
+			return p
+		}
+
+		// neighbors
+		for _, n := range []*core.Cell{
+			m.Cell(c.XCol()+1, c.YRow()),
+			m.Cell(c.XCol()-1, c.YRow()),
+			m.Cell(c.XCol(), c.YRow()+1),
+			m.Cell(c.XCol(), c.YRow()-1),
+		} {
+			if _, ok := prev[n]; ok || !passable(n, avoid) {
+				continue
+			}
+			prev[n] = c
+			queue = append(queue, n)
+		}
+	}
+	return nil
+}
+
+// drawPath returns the planned path as debug shapes: a line from the ship to the star and a circle around the star.
+func drawPath(pos *core.Vector, p []*core.Cell) []core.Shape {
+	if len(p) == 0 {
+		return nil
+	}
+	shapes := make([]core.Shape, 0, len(p)+1)
+	from := pos
+	for _, c := range p {
+		to := c.Center()
+		shapes = append(shapes, core.Shape{Kind: core.ShapeLine, X: from.X(), Y: from.Y(), X2: to.X(), Y2: to.Y()})
+		from = to
+	}
+	return append(shapes, core.Shape{Kind: core.ShapeCircle, X: from.X(), Y: from.Y(), Radius: core.CellRadius})
+}
+
+// passable returns true if the bot may drive on the cell.
+// The void and blocks are never passable, anti-stars and crumble cells only if avoid is false.
+func passable(c *core.Cell, avoid bool) bool {
+	switch c.Type() {
+	case core.None, core.Blocked:
+		return false
+	case core.Anti, core.Crumble:
+		return !avoid
+	default:
+		return true
+	}
+}
@@ -17,10 +17,26 @@ const (
 	Star    = 'x' // Star cell: increases the score on contact and then disappears (Tile)
 	Anti    = 'a' // Anti cell: reduce the score on contact and then disappears (Tile)
 	Spawn   = 'o' // Spawn cell: point where players are randomly placed
+
+	Shield   = 'h' // Shield cell: protects against the next bump and then disappears (Tile)
+	Mass     = 'm' // Mass cell: increases the ship mass for a while and then disappears (Tile)
+	Teleport = 't' // Teleport cell: moves the ship to the paired teleport cell (see WorldMap.Teleport)
+	Crumble  = 'c' // Crumble cell: can be driven on, but turns into None after a ship has crossed it
+
+	ConveyorUp    = '^' // Conveyor cell: pushes the ship up as long as it is on this cell
+	ConveyorDown  = 'v' // Conveyor cell: pushes the ship down as long as it is on this cell
+	ConveyorLeft  = '<' // Conveyor cell: pushes the ship left as long as it is on this cell
+	ConveyorRight = '>' // Conveyor cell: pushes the ship right as long as it is on this cell
+
+	Hill = 'k' // Hill cell: zone of the king of the hill mode (see KingOfTheHill)
+	Flag = 'f' // Flag cell: flag of the capture the flag mode (see CaptureTheFlag)
+	Goal = 'g' // Goal cell: base of the capture the flag mode (see CaptureTheFlag)
 )
 
 // CellTypes all supported cell types as slice
-var CellTypes = []byte{None, Blocked, Boost, Slow, Tile, Star, Anti, Spawn}
+var CellTypes = []byte{None, Blocked, Boost, Slow, Tile, Star, Anti, Spawn,
+	Shield, Mass, Teleport, Crumble, ConveyorUp, ConveyorDown, ConveyorLeft, ConveyorRight,
+	Hill, Flag, Goal}
 
 // CellSize is the dimension of the square cell
 const CellSize = 40.0 // cell image 40x40
@@ -107,6 +123,23 @@ func (c *Cell) Center() *Vector {
 	return c.center.Clone()
 }
 
+// Conveyor returns the push direction (unit vector) of a conveyor cell.
+// All other cell types return a zero vector.
+func (c *Cell) Conveyor() *Vector {
+	switch c.cType {
+	case ConveyorUp:
+		return NewVector(0, -1)
+	case ConveyorDown:
+		return NewVector(0, 1)
+	case ConveyorLeft:
+		return NewVector(-1, 0)
+	case ConveyorRight:
+		return NewVector(1, 0)
+	default:
+		return new(Vector)
+	}
+}
+
 //--------  Setter  --------------------------------------------------------------------------------------------------//
 
 // SetType change the CellTypes.
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ShipClass defines the physical attributes of a ship.
+// (see ShipClasses and ParseShipClass)
+type ShipClass struct {
+	Name   string
+	Mass   float64 // base mass (collision winner and knock back, see Ship.Mass)
+	Radius float64 // collision radius (see Ship.Collide)
+	Accel  float64 // how fast is acceleration converted to velocity
+	Grip   float64 // rolling resistance limit the max. speed
+}
+
+// ShipClasses all supported ship classes.
+// The first class is the default.
+var ShipClasses = []ShipClass{
+	{Name: "normal", Mass: 1.0, Radius: CellRadius, Accel: 0.15, Grip: 0.985},
+	{Name: "heavy", Mass: 1.6, Radius: CellRadius + 2, Accel: 0.10, Grip: 0.985},
+	{Name: "light", Mass: 0.6, Radius: CellRadius - 4, Accel: 0.20, Grip: 0.980},
+}
+
+// ParseShipClass returns the ship class by its name.
+// An empty name returns the default class (normal).
+func ParseShipClass(name string) (ShipClass, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return ShipClasses[0], nil
+	}
+	names := make([]string, 0, len(ShipClasses))
+	for _, c := range ShipClasses {
+		if c.Name == name {
+			return c, nil
+		}
+		names = append(names, c.Name)
+	}
+	return ShipClass{}, fmt.Errorf("ship class must be %s", strings.Join(names, ", "))
+}
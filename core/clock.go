@@ -0,0 +1,204 @@
+package core
+
+import (
+	"time"
+)
+
+// TPS is the default number of world updates (ticks) per second.
+const TPS = 60
+
+// maxLag limits the real time a single Step can catch up.
+// A stalled process (debugger, sleeping laptop, ...) does not
+// lead to thousands of world updates at once.
+const maxLag = 250 * time.Millisecond
+
+// Clock drives the WorldMap.Update() calls with a fixed timestep.
+// The simulation speed is independent of the rendering speed and
+// the machine load: missed ticks are caught up in the next Step.
+// (see NewClock)
+type Clock struct {
+	world *WorldMap
+	tps   int     // world updates per second (at speed 1.0)
+	speed float64 // speed factor (0.5x, 2x, 10x, ...)
+
+	last time.Time     // time of the last Step call
+	lag  time.Duration // simulation time that has not yet been processed
+
+	prev *Snapshot // world state before the last tick
+	curr *Snapshot // world state after the last tick
+}
+
+// NewClock create a new clock for the given world.
+// If tps is invalid, then the default (TPS) is used.
+func NewClock(world *WorldMap, tps int) *Clock {
+	if tps <= 0 {
+		tps = TPS
+	}
+	snap := NewSnapshot(world)
+	return &Clock{
+		world: world,
+		tps:   tps,
+		speed: 1.0,
+		last:  time.Now(),
+		lag:   0,
+		prev:  snap,
+		curr:  snap,
+	}
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// TPS returns the world updates per second at speed 1.0.
+func (c *Clock) TPS() int {
+	return c.tps
+}
+
+// Speed returns the speed factor (see SetSpeed).
+func (c *Clock) Speed() float64 {
+	return c.speed
+}
+
+// Interval returns the real time between two ticks (speed included).
+func (c *Clock) Interval() time.Duration {
+	return time.Duration(float64(time.Second) / (float64(c.tps) * c.speed))
+}
+
+// Alpha returns the progress (0.0 to 1.0) between the last two snapshots.
+// It is used to interpolate the ship positions while rendering.
+func (c *Clock) Alpha() float64 {
+	a := float64(c.lag+time.Since(c.last)) / float64(c.Interval())
+	if a < 0 {
+		return 0
+	}
+	if a > 1 {
+		return 1
+	}
+	return a
+}
+
+// Position returns the interpolated position of a ship between the last two ticks.
+// Ships that have jumped (spawn, teleport, ...) are not interpolated.
+// The value is immutable (vector clone).
+func (c *Clock) Position(s *Ship) *Vector {
+	p0, ok0 := c.prev.Position(s.PlayerID())
+	p1, ok1 := c.curr.Position(s.PlayerID())
+	if !ok0 || !ok1 {
+		return s.Position()
+	}
+
+	// no interpolation for jumps
+	d := p1.Clone()
+	d.Add(p0, -1)
+	if d.Length() > 2*CellSize {
+		return p1
+	}
+
+	// p0 + (p1-p0)*alpha
+	p0.Add(d, c.Alpha())
+	return p0
+}
+
+//--------  Setter  --------------------------------------------------------------------------------------------------//
+
+// SetSpeed changes the speed factor of the simulation (e.g. 0.5, 2 or 10).
+// If the factor is invalid, then the default (1.0) is used.
+func (c *Clock) SetSpeed(speed float64) {
+	if speed <= 0 {
+		speed = 1.0
+	}
+	c.speed = speed
+}
+
+// Reset discards the elapsed time since the last Step (e.g. after a pause).
+// Without a reset, the next Step catches up the pause (up to maxLag).
+func (c *Clock) Reset() {
+	c.last = time.Now()
+	c.lag = 0
+}
+
+// Step runs all world updates that are due since the last call
+// and returns the number of executed ticks.
+// Call this as often as you like (e.g. every frame).
+func (c *Clock) Step() int {
+	// elapsed time
+	now := time.Now()
+	elapsed := now.Sub(c.last)
+	c.last = now
+	if elapsed > maxLag {
+		elapsed = maxLag
+	}
+	c.lag += elapsed
+
+	// fixed ticks with catch-up
+	interval := c.Interval()
+	ticks := 0
+	for c.lag >= interval {
+		c.world.Update()
+		c.prev = c.curr
+		c.curr = NewSnapshot(c.world)
+		c.lag -= interval
+		ticks++
+	}
+
+	// return
+	return ticks
+}
+
+// Run calls Step in an endless loop.
+// This call is blocking (see headless mode).
+func (c *Clock) Run() {
+	c.RunUntil(func() bool { return false })
+}
+
+// RunUntil calls Step until stop returns true (checked after each Step).
+// Between two steps, it sleeps until the next tick is due.
+// This call is blocking (e.g. headless recording).
+func (c *Clock) RunUntil(stop func() bool) {
+	for {
+		c.Step()
+		if stop() {
+			return
+		}
+		if wait := c.Interval() - c.lag; wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+}
+
+//--------  Snapshot  ------------------------------------------------------------------------------------------------//
+
+// Snapshot stores the ship positions of one tick.
+// (see NewSnapshot)
+type Snapshot struct {
+	iteration uint64
+	positions map[int]*Vector // player id -> position
+}
+
+// NewSnapshot stores the current ship positions of the world.
+func NewSnapshot(m *WorldMap) *Snapshot {
+	players := m.Players()
+	positions := make(map[int]*Vector, len(players))
+	for _, p := range players {
+		positions[p.PlayerID()] = p.Position()
+	}
+	iteration, _, _ := m.Stats()
+	return &Snapshot{
+		iteration: iteration,
+		positions: positions,
+	}
+}
+
+// Iteration returns the world iteration of this snapshot.
+func (s *Snapshot) Iteration() uint64 {
+	return s.iteration
+}
+
+// Position returns the stored position of a player.
+// The value is immutable (vector clone).
+func (s *Snapshot) Position(playerID int) (*Vector, bool) {
+	p, ok := s.positions[playerID]
+	if !ok {
+		return nil, false
+	}
+	return p.Clone(), true
+}
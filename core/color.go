@@ -0,0 +1,59 @@
+package core
+
+import (
+	"errors"
+	"hash/fnv"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// Colors all named ship colors with their RGB value (see ParseColor)
+var Colors = map[string]color.RGBA{
+	"red":    {R: 224, G: 0, B: 0, A: 255},
+	"blue":   {R: 0, G: 96, B: 224, A: 255},
+	"green":  {R: 0, G: 224, B: 96, A: 255},
+	"orange": {R: 224, G: 96, B: 0, A: 255},
+}
+
+// ParseColor returns the RGB value of a ship color.
+// A color is either a named color (red, blue, green or orange, see Colors)
+// or a hex RGB color (e.g. '#ff8800').
+func ParseColor(s string) (color.RGBA, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	// named color
+	if c, ok := Colors[s]; ok {
+		return c, nil
+	}
+
+	// hex color
+	if len(s) != 7 || s[0] != '#' {
+		return color.RGBA{}, errors.New("player color must be red, blue, green, orange or a hex color (#rrggbb)")
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return color.RGBA{}, errors.New("player color must be red, blue, green, orange or a hex color (#rrggbb)")
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, nil
+}
+
+// teamPalette are the marker colors of the teams (see TeamColor).
+var teamPalette = []color.RGBA{
+	{R: 255, G: 80, B: 80, A: 255},
+	{R: 80, G: 140, B: 255, A: 255},
+	{R: 80, G: 220, B: 100, A: 255},
+	{R: 255, G: 170, B: 40, A: 255},
+	{R: 200, G: 90, B: 255, A: 255},
+	{R: 60, G: 230, B: 230, A: 255},
+	{R: 255, G: 240, B: 60, A: 255},
+	{R: 255, G: 120, B: 200, A: 255},
+}
+
+// TeamColor returns the marker color of a team.
+// The same team name always has the same color.
+func TeamColor(team string) color.RGBA {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(team))
+	return teamPalette[h.Sum32()%uint32(len(teamPalette))]
+}
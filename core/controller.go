@@ -0,0 +1,24 @@
+package core
+
+// Controller steers a ship inside the game process (e.g. built-in bots).
+// Ships with a controller are neither controlled locally nor remotely.
+// (see Ship.SetController)
+type Controller interface {
+	// Control is called once per tick before the ship is updated.
+	// Use Ship.Move, Ship.Dash and Ship.Brake to steer the ship.
+	Control(m *WorldMap, s *Ship)
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// Controller returns the controller of the ship or nil (see SetController).
+func (s *Ship) Controller() Controller {
+	return s.controller
+}
+
+//--------  Setter  --------------------------------------------------------------------------------------------------//
+
+// SetController sets the controller of the ship (nil removes the controller).
+func (s *Ship) SetController(c Controller) {
+	s.controller = c
+}
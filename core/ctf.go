@@ -0,0 +1,165 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// interface check: GameMode
+var _ GameMode = (*CaptureTheFlag)(nil)
+
+// CaptureTheFlag is a game mode with flags (see Flag cell) and bases (see Goal cell).
+// A base is an area of connected goal cells (see WorldMap.zones), each flag belongs to the nearest base.
+//
+// The bases are ordered by their first cell (top to bottom, left to right) and owned by
+// the teams of the map header setting 'bases' in this order (see MapInfo.Bases).
+// Without this setting, the base i is owned by the player with the PlayerID i (free for all).
+//
+// A ship picks up a flag of another base by driving over it and scores by carrying it to its own base.
+// The flag returns to its cell after a capture or if the carrier falls into the void or dies.
+// Each ship can only carry one flag. Ships without a base can steal flags, but never score.
+// (see NewCaptureTheFlag)
+type CaptureTheFlag struct {
+	Points int // points per capture
+
+	carrier map[*Cell]*Ship // flag cell -> carrier
+	spawns  map[*Cell]int   // flag cell -> carrier spawns at pick up (see Ship.Spawns)
+
+	bases    []string      // base owners (teams, see MapInfo.Bases)
+	count    int           // number of bases
+	baseOf   map[*Cell]int // goal cell -> base index (see init)
+	flagBase map[*Cell]int // flag cell -> base index of the nearest base (-1 = no base)
+}
+
+// NewCaptureTheFlag returns the capture the flag mode with default settings.
+func NewCaptureTheFlag() *CaptureTheFlag {
+	return &CaptureTheFlag{
+		Points:  100,
+		carrier: make(map[*Cell]*Ship),
+		spawns:  make(map[*Cell]int),
+	}
+}
+
+// Name returns 'ctf'.
+func (c *CaptureTheFlag) Name() string {
+	return "ctf"
+}
+
+// Carrying returns true if the ship carries a flag.
+func (c *CaptureTheFlag) Carrying(s *Ship) bool {
+	for _, p := range c.carrier {
+		if p == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Update processes pick ups, captures and lost flags.
+func (c *CaptureTheFlag) Update(m *WorldMap) {
+	if c.baseOf == nil {
+		c.init(m)
+	}
+
+	// lost flags: the carrier has died or respawned
+	for flag, p := range c.carrier {
+		if !p.IsAlive() || p.Spawns() != c.spawns[flag] {
+			c.reset(m, flag)
+		}
+	}
+
+	for _, p := range m.Players() {
+		if !p.IsAlive() {
+			continue
+		}
+		cell := m.CellByVector(p.position)
+		home := c.base(p)
+
+		// pick up (not the own flags)
+		if cell.Type() == Flag && !c.Carrying(p) && (home < 0 || c.flagBase[cell] != home) {
+			c.carrier[cell] = p
+			c.spawns[cell] = p.Spawns()
+			cell.SetType(Tile)
+			m.mapChanged = true
+			continue
+		}
+
+		// capture (only at the own base)
+		if base, ok := c.baseOf[cell]; ok && cell.Type() == Goal && base == home {
+			for flag, carrier := range c.carrier {
+				if carrier == p {
+					m.emit(Event{Type: EventObjective, Ship: p, Cell: cell, CellType: Goal, Points: c.Points})
+					c.reset(m, flag)
+				}
+			}
+		}
+	}
+}
+
+// Status returns all carried flags ('Flags:{x},{y},{PlayerID};...').
+// X and Y are the flag cell, the PlayerID is the carrier.
+// Flags on their cell are part of the MAP block.
+func (c *CaptureTheFlag) Status() string {
+	flags := make([]string, 0)
+	for flag, p := range c.carrier {
+		flags = append(flags, fmt.Sprintf("%d,%d,%d;", flag.XCol(), flag.YRow(), p.PlayerID()))
+	}
+	sort.Strings(flags)
+	return "Flags:" + strings.Join(flags, "") + "\n"
+}
+
+// base returns the base index of the ship or -1 (no base).
+func (c *CaptureTheFlag) base(s *Ship) int {
+	if len(c.bases) == 0 {
+		if s.PlayerID() < c.count {
+			return s.PlayerID()
+		}
+		return -1
+	}
+	for i, team := range c.bases {
+		if team == s.Team() && s.Team() != "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// init finds the bases of the map and assigns each flag to the nearest base.
+// Goal and flag cells that are added later (e.g. map events) are not part of a base.
+func (c *CaptureTheFlag) init(m *WorldMap) {
+	zones := m.zones(Goal)
+	c.bases = m.info.Bases
+	c.count = len(zones)
+	c.baseOf = make(map[*Cell]int)
+	c.flagBase = make(map[*Cell]int)
+	for i, z := range zones {
+		for _, g := range z {
+			c.baseOf[g] = i
+		}
+	}
+
+	for _, z := range m.zones(Flag) {
+		for _, f := range z {
+			c.flagBase[f] = -1
+			best := 0
+			for g, i := range c.baseOf {
+				dx, dy := g.XCol()-f.XCol(), g.YRow()-f.YRow()
+				d := dx*dx + dy*dy
+				if c.flagBase[f] < 0 || d < best || (d == best && i < c.flagBase[f]) {
+					c.flagBase[f], best = i, d
+				}
+			}
+		}
+	}
+}
+
+// reset returns a flag to its cell.
+func (c *CaptureTheFlag) reset(m *WorldMap, flag *Cell) {
+	delete(c.carrier, flag)
+	delete(c.spawns, flag)
+	if flag.Type() != None { // removed (see WorldMap.ShrinkArena)
+		flag.SetType(Flag)
+		m.mapChanged = true
+	}
+}
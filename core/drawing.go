@@ -0,0 +1,135 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ShapeKind is the type of a debug shape (see Shape).
+type ShapeKind int
+
+// Shapes of the DRAW command
+const (
+	ShapeLine   ShapeKind = iota // line from X,Y to X2,Y2
+	ShapeCircle                  // circle at X,Y with Radius
+	ShapeLabel                   // Text at X,Y
+)
+
+// maxShapes limits the shapes of a drawing
+const maxShapes = 1000
+
+// Shape is a debug shape of a bot (e.g. its planned path).
+// The coordinates are world pixels like the ship positions (see CellSize).
+// The simulator GUI draws the shapes in the player color.
+type Shape struct {
+	Kind   ShapeKind
+	X, Y   float64 // line start, circle center or label position
+	X2, Y2 float64 // line end
+	Radius float64 // circle radius
+	Text   string  // label text
+}
+
+// ParseShape parses the shape of a DRAW command (without 'DRAW|').
+//
+//	LINE|{x1}|{y1}|{x2}|{y2}
+//	CIRCLE|{x}|{y}|{radius}
+//	LABEL|{x}|{y}|{text}
+func ParseShape(param []string) (Shape, error) {
+	if len(param) < 1 {
+		return Shape{}, errors.New("missing shape")
+	}
+
+	// numbers
+	kind := strings.ToUpper(strings.TrimSpace(param[0]))
+	var n int
+	switch kind {
+	case "LINE":
+		n = 4
+	case "CIRCLE":
+		n = 3
+	case "LABEL":
+		n = 2
+	default:
+		return Shape{}, fmt.Errorf("invalid shape '%s': use LINE, CIRCLE or LABEL", param[0])
+	}
+	if len(param) < n+1 {
+		return Shape{}, fmt.Errorf("shape %s needs %d numbers", kind, n)
+	}
+	f := make([]float64, n)
+	for i := range f {
+		v, err := strconv.ParseFloat(strings.TrimSpace(param[i+1]), 64)
+		if err != nil {
+			return Shape{}, fmt.Errorf("invalid number '%s'", param[i+1])
+		}
+		f[i] = v
+	}
+
+	// shape
+	switch kind {
+	case "LINE":
+		return Shape{Kind: ShapeLine, X: f[0], Y: f[1], X2: f[2], Y2: f[3]}, nil
+	case "CIRCLE":
+		return Shape{Kind: ShapeCircle, X: f[0], Y: f[1], Radius: f[2]}, nil
+	default:
+		text := strings.Join(param[n+1:], "|")
+		if r := []rune(text); len(r) > 100 {
+			text = string(r[:100]) // max. 100 runes (no broken UTF-8)
+		}
+		return Shape{Kind: ShapeLabel, X: f[0], Y: f[1], Text: text}, nil
+	}
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// Drawing returns the shown debug shapes of the ship (see SetDrawing).
+func (s *Ship) Drawing() []Shape {
+	s.drawMux.Lock()
+	defer s.drawMux.Unlock()
+	return s.drawing
+}
+
+//--------  Setter  --------------------------------------------------------------------------------------------------//
+
+// SetDrawing replaces the shown debug shapes of the ship (max. 1000).
+// Built-in bots can call it directly, remote bots use the DRAW command (see Draw).
+func (s *Ship) SetDrawing(shapes []Shape) {
+	if len(shapes) > maxShapes {
+		shapes = shapes[:maxShapes]
+	}
+	s.drawMux.Lock()
+	defer s.drawMux.Unlock()
+	s.drawing = shapes
+}
+
+// Draw executes a DRAW command of a remote bot (without 'DRAW|').
+// The shapes are collected until SHOW replaces the shown drawing (no flickering).
+//
+//	LINE|..., CIRCLE|..., LABEL|...   add a shape (see ParseShape)
+//	SHOW                              show the collected shapes
+//	CLEAR                             remove all shapes
+func (s *Ship) Draw(param []string) error {
+	if len(param) == 1 {
+		switch strings.ToUpper(strings.TrimSpace(param[0])) {
+		case "SHOW":
+			shapes := s.drawBuf
+			s.drawBuf = nil
+			s.SetDrawing(shapes)
+			return nil
+		case "CLEAR":
+			s.drawBuf = nil
+			s.SetDrawing(nil)
+			return nil
+		}
+	}
+
+	shape, err := ParseShape(param)
+	if err != nil {
+		return err
+	}
+	if len(s.drawBuf) < maxShapes {
+		s.drawBuf = append(s.drawBuf, shape)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// interface check: GameMode
+var _ GameMode = (*KingOfTheHill)(nil)
+
+// KingOfTheHill is a game mode with one or more hill zones (see Hill cell).
+// A hill zone is an area of connected hill cells (see WorldMap.zones).
+// A ship that is alone on a hill zone is the king of this zone and gets points while it stays there.
+// Each zone has its own king, contested zones (two or more ships) give no points.
+// (see NewKingOfTheHill)
+type KingOfTheHill struct {
+	Interval int // ticks per point award
+	Points   int // points per award
+
+	zone  map[*Cell]int // hill cell -> zone index (see init)
+	kings []*Ship       // current king per zone or nil
+	ticks []int         // ticks of the current king per zone on the hill
+}
+
+// NewKingOfTheHill returns the king of the hill mode with default settings.
+func NewKingOfTheHill() *KingOfTheHill {
+	return &KingOfTheHill{
+		Interval: 6, // 10 points per second
+		Points:   1,
+	}
+}
+
+// Name returns 'koth'.
+func (k *KingOfTheHill) Name() string {
+	return "koth"
+}
+
+// Kings returns the current king of each hill zone (nil = no king).
+// The zones are ordered by their first cell (top to bottom, left to right).
+func (k *KingOfTheHill) Kings() []*Ship {
+	return k.kings
+}
+
+// Update finds the king of each hill zone and awards the points.
+func (k *KingOfTheHill) Update(m *WorldMap) {
+	if k.zone == nil {
+		k.init(m)
+	}
+
+	// all ships per hill zone
+	onHill := make([][]*Ship, len(k.kings))
+	for _, p := range m.Players() {
+		if !p.IsAlive() {
+			continue
+		}
+		cell := m.CellByVector(p.position)
+		if i, ok := k.zone[cell]; ok && cell.Type() == Hill {
+			onHill[i] = append(onHill[i], p)
+		}
+	}
+
+	for i, ships := range onHill {
+
+		// no king or contested
+		if len(ships) != 1 {
+			k.kings[i] = nil
+			k.ticks[i] = 0
+			continue
+		}
+
+		// new king
+		if k.kings[i] != ships[0] {
+			k.kings[i] = ships[0]
+			k.ticks[i] = 0
+		}
+
+		// award points
+		k.ticks[i]++
+		if k.Interval > 0 && k.ticks[i]%k.Interval == 0 {
+			king := k.kings[i]
+			m.emit(Event{Type: EventObjective, Ship: king, Cell: m.CellByVector(king.position), CellType: Hill, Points: k.Points})
+		}
+	}
+}
+
+// Status returns the current king of each hill zone ('Hill:{PlayerID},...', -1 = no king).
+func (k *KingOfTheHill) Status() string {
+	ids := make([]string, len(k.kings))
+	for i, p := range k.kings {
+		ids[i] = "-1"
+		if p != nil {
+			ids[i] = fmt.Sprint(p.PlayerID())
+		}
+	}
+	if len(ids) == 0 {
+		ids = append(ids, "-1") // before the first update
+	}
+	return "Hill:" + strings.Join(ids, ",") + "\n"
+}
+
+// init finds the hill zones of the map.
+// Hill cells that are added later (e.g. map events) are not part of a zone.
+func (k *KingOfTheHill) init(m *WorldMap) {
+	zones := m.zones(Hill)
+	k.zone = make(map[*Cell]int)
+	k.kings = make([]*Ship, len(zones))
+	k.ticks = make([]int, len(zones))
+	for i, z := range zones {
+		for _, c := range z {
+			k.zone[c] = i
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MapEvent is a scripted change of the map at a given iteration.
+// Map events are defined in the map header, one event per line:
+//
+//	event: {at}[/{every}] {action} {args}
+//
+//	event: 3600/600 shrink                 (the outer ring turns into void, see ShrinkArena)
+//	event: 600 set 4,2-8,2 b               (all cells of the area get the type 'b')
+//	event: 300/300 toggle 10,4-10,6 # .    (swaps the types '#' and '.', e.g. doors)
+//	event: 0/30 shift 2,8-20,8 1,0         (moves the area one column to the right, wrap around)
+//
+// Areas are given as '{x},{y}' or '{x1},{y1}-{x2},{y2}' (grid columns and rows, inclusive).
+// Cell types are map characters, 'none' is the void (' ').
+// Blocked cells are not placed under a ship (the cell is skipped).
+// All changes are sent with the next MAP block.
+type MapEvent struct {
+	At     uint64 // first iteration
+	Every  uint64 // repeat interval (0 = only once)
+	Action string // shrink, set, toggle or shift
+
+	X1, Y1, X2, Y2 int    // area (set, toggle and shift)
+	Types          []byte // cell types (set: 1, toggle: 2)
+	DX, DY         int    // offset (shift)
+}
+
+// MapEventActions all supported map event actions (see MapEvent)
+var MapEventActions = []string{"shrink", "set", "toggle", "shift"}
+
+// ParseMapEvent parses a map event (e.g. '300/300 toggle 10,4-10,6 # .', see MapEvent).
+func ParseMapEvent(s string) (MapEvent, error) {
+	var e MapEvent
+	f := strings.Fields(s)
+	if len(f) < 2 {
+		return e, errors.New("use '{at}[/{every}] {action} {args}'")
+	}
+
+	// timing
+	var err error
+	at := strings.SplitN(f[0], "/", 2)
+	if e.At, err = strconv.ParseUint(at[0], 10, 64); err != nil {
+		return e, fmt.Errorf("invalid iteration '%s'", f[0])
+	}
+	if len(at) == 2 {
+		if e.Every, err = strconv.ParseUint(at[1], 10, 64); err != nil {
+			return e, fmt.Errorf("invalid interval '%s'", f[0])
+		}
+	}
+
+	// action
+	e.Action = strings.ToLower(f[1])
+	args := f[2:]
+	switch e.Action {
+	case "shrink":
+		if len(args) != 0 {
+			return e, errors.New("use 'shrink' without args")
+		}
+	case "set":
+		if len(args) != 2 {
+			return e, errors.New("use 'set {area} {type}'")
+		}
+		e.Types, err = parseCellTypes(args[1:])
+	case "toggle":
+		if len(args) != 3 {
+			return e, errors.New("use 'toggle {area} {type} {type}'")
+		}
+		e.Types, err = parseCellTypes(args[1:])
+	case "shift":
+		if len(args) != 2 {
+			return e, errors.New("use 'shift {area} {dx},{dy}'")
+		}
+		e.DX, e.DY, err = parsePoint(args[1])
+	default:
+		return e, fmt.Errorf("invalid action '%s': use %s", e.Action, strings.Join(MapEventActions, ", "))
+	}
+	if err != nil {
+		return e, err
+	}
+
+	// area
+	if e.Action != "shrink" {
+		e.X1, e.Y1, e.X2, e.Y2, err = parseArea(args[0])
+	}
+	return e, err
+}
+
+// String returns the map event as defined in the map header.
+func (e MapEvent) String() string {
+	sb := new(strings.Builder)
+	sb.WriteString(fmt.Sprintf("%d", e.At))
+	if e.Every > 0 {
+		sb.WriteString(fmt.Sprintf("/%d", e.Every))
+	}
+	sb.WriteString(" " + e.Action)
+	if e.Action == "shrink" {
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf(" %d,%d-%d,%d", e.X1, e.Y1, e.X2, e.Y2))
+	for _, t := range e.Types {
+		if t == None {
+			sb.WriteString(" none")
+		} else {
+			sb.WriteString(" " + string(t))
+		}
+	}
+	if e.Action == "shift" {
+		sb.WriteString(fmt.Sprintf(" %d,%d", e.DX, e.DY))
+	}
+	return sb.String()
+}
+
+// Due returns true if the event is executed in the given iteration.
+func (e MapEvent) Due(iteration uint64) bool {
+	if iteration < e.At {
+		return false
+	}
+	if e.Every == 0 {
+		return iteration == e.At
+	}
+	return (iteration-e.At)%e.Every == 0
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// MapEvents returns all scripted map events (see MapEvent).
+func (m *WorldMap) MapEvents() []MapEvent {
+	return m.mapEvents
+}
+
+//--------  Setter  --------------------------------------------------------------------------------------------------//
+
+// AddMapEvent adds a scripted map event (see MapEvent).
+// Returns an error if the area is outside the grid.
+func (m *WorldMap) AddMapEvent(e MapEvent) error {
+	if e.Action != "shrink" && (e.X1 < 0 || e.Y1 < 0 || e.X2 >= m.xWidth || e.Y2 >= m.yHeight) {
+		return fmt.Errorf("area %d,%d-%d,%d is outside the grid", e.X1, e.Y1, e.X2, e.Y2)
+	}
+	m.mapEvents = append(m.mapEvents, e)
+	return nil
+}
+
+//--------  Update  --------------------------------------------------------------------------------------------------//
+
+// updateMapEvents executes all due map events.
+// This function is called from WorldMap.Update().
+func (m *WorldMap) updateMapEvents() {
+	changed := false
+	for _, e := range m.mapEvents {
+		if !e.Due(m.iteration) {
+			continue
+		}
+		switch e.Action {
+		case "shrink":
+			m.ShrinkArena()
+		case "set":
+			m.eachCell(e, func(c *Cell) {
+				m.changeCell(c, e.Types[0])
+			})
+		case "toggle":
+			m.eachCell(e, func(c *Cell) {
+				if c.Type() == e.Types[0] {
+					m.changeCell(c, e.Types[1])
+				} else if c.Type() == e.Types[1] {
+					m.changeCell(c, e.Types[0])
+				}
+			})
+		case "shift":
+			m.shiftArea(e)
+		}
+		changed = true
+	}
+
+	if changed {
+		m.linkTeleports()
+		m.mapChanged = true
+	}
+}
+
+// shiftArea moves all cell types of the area by DX and DY (wrap around).
+func (m *WorldMap) shiftArea(e MapEvent) {
+	w := e.X2 - e.X1 + 1
+	h := e.Y2 - e.Y1 + 1
+
+	// copy types
+	types := make([]byte, w*h)
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			types[x*h+y] = m.grid[e.X1+x][e.Y1+y].Type()
+		}
+	}
+
+	// move types
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			tx := ((x+e.DX)%w + w) % w
+			ty := ((y+e.DY)%h + h) % h
+			m.changeCell(m.grid[e.X1+tx][e.Y1+ty], types[x*h+y])
+		}
+	}
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+// eachCell calls f for all cells of the event area.
+func (m *WorldMap) eachCell(e MapEvent, f func(c *Cell)) {
+	for x := e.X1; x <= e.X2; x++ {
+		for y := e.Y1; y <= e.Y2; y++ {
+			f(m.grid[x][y])
+		}
+	}
+}
+
+// changeCell sets the cell type.
+// Blocked cells are not placed under a ship.
+func (m *WorldMap) changeCell(c *Cell, t byte) {
+	if t == Blocked && m.isTouched(c) {
+		return
+	}
+	c.SetType(t)
+}
+
+// parseArea parses '{x},{y}' or '{x1},{y1}-{x2},{y2}'.
+// The coordinates are sorted (x1 <= x2 and y1 <= y2).
+func parseArea(s string) (x1, y1, x2, y2 int, err error) {
+	p := strings.SplitN(s, "-", 2)
+	if x1, y1, err = parsePoint(p[0]); err != nil {
+		return
+	}
+	x2, y2 = x1, y1
+	if len(p) == 2 {
+		if x2, y2, err = parsePoint(p[1]); err != nil {
+			return
+		}
+	}
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+	return
+}
+
+// parsePoint parses '{x},{y}'.
+func parsePoint(s string) (x, y int, err error) {
+	p := strings.Split(s, ",")
+	if len(p) != 2 {
+		return 0, 0, fmt.Errorf("invalid point '%s': use '{x},{y}'", s)
+	}
+	x, errX := strconv.Atoi(p[0])
+	y, errY := strconv.Atoi(p[1])
+	if errX != nil || errY != nil {
+		return 0, 0, fmt.Errorf("invalid point '%s': use '{x},{y}'", s)
+	}
+	return x, y, nil
+}
+
+// parseCellTypes parses map characters ('none' is the void).
+func parseCellTypes(args []string) ([]byte, error) {
+	types := make([]byte, 0, len(args))
+	for _, a := range args {
+		if strings.ToLower(a) == "none" {
+			types = append(types, None)
+			continue
+		}
+		if len(a) != 1 || bytes.IndexByte(CellTypes, a[0]) < 0 {
+			return nil, fmt.Errorf("invalid cell type '%s'", a)
+		}
+		types = append(types, a[0])
+	}
+	return types, nil
+}
@@ -0,0 +1,24 @@
+package core
+
+// MapInfo contains the metadata of a map (see map header).
+//
+//	name: Arena
+//	author: Jane Doe
+//	description: Free for all with 16 spawns
+//	players: 8
+type MapInfo struct {
+	Name        string // display name (default: file name, see LoadWorldMap)
+	Author      string
+	Description string
+	Players     int      // recommended number of players (0 = not set, see MaxPlayers)
+	Endtime     uint64   // end time of the map header (0 = not set)
+	Bases       []string // teams of the capture the flag bases (see CaptureTheFlag)
+	Unknown     []string // keys of unknown header settings (ignored, see HeaderKeys)
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// Info returns the metadata of the map (see MapInfo).
+func (m *WorldMap) Info() MapInfo {
+	return m.info
+}
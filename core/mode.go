@@ -0,0 +1,88 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GameMode is an optional rule set on top of the WorldMap.
+// The default scoring (stars, bumps and knock-outs) is always active,
+// the game mode adds its own goals, map markers and status output.
+// (see WorldMap.SetGameMode)
+type GameMode interface {
+	// Name returns the short name of the game mode (see NewGameMode).
+	Name() string
+
+	// Update is called once per tick after all ships are updated.
+	Update(m *WorldMap)
+
+	// Status returns additional lines for the STATUS block.
+	// Each line is formatted as 'key:value\n'.
+	Status() string
+}
+
+// GameModes all supported game mode names (see NewGameMode)
+var GameModes = []string{"koth", "ctf"}
+
+// NewGameMode returns a game mode with default settings by its name.
+// Supported names are 'koth' (KingOfTheHill) and 'ctf' (CaptureTheFlag).
+// An empty name returns nil (no game mode).
+func NewGameMode(name string) (GameMode, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "":
+		return nil, nil
+	case "koth":
+		return NewKingOfTheHill(), nil
+	case "ctf":
+		return NewCaptureTheFlag(), nil
+	default:
+		return nil, fmt.Errorf("invalid game mode '%s': use %s", name, strings.Join(GameModes, ", "))
+	}
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// GameMode returns the active game mode or nil.
+func (m *WorldMap) GameMode() GameMode {
+	return m.gameMode
+}
+
+//--------  Setter  --------------------------------------------------------------------------------------------------//
+
+// SetGameMode sets the game mode (nil disables the game mode).
+func (m *WorldMap) SetGameMode(mode GameMode) {
+	m.gameMode = mode
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+// zones returns the connected areas of a cell type (horizontal and vertical neighbors, e.g. hill zones or bases).
+// The zones are ordered by their first cell (top to bottom, left to right).
+func (m *WorldMap) zones(t byte) [][]*Cell {
+	seen := make(map[*Cell]bool)
+	zones := make([][]*Cell, 0)
+	for yRow := 0; yRow < m.yHeight; yRow++ {
+		for xCol := 0; xCol < m.xWidth; xCol++ {
+			start := m.Cell(xCol, yRow)
+			if start.Type() != t || seen[start] {
+				continue
+			}
+
+			// flood fill
+			seen[start] = true
+			zone := []*Cell{start}
+			for i := 0; i < len(zone); i++ {
+				c := zone[i]
+				for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+					n := m.Cell(c.XCol()+d[0], c.YRow()+d[1])
+					if n.Type() == t && !seen[n] {
+						seen[n] = true
+						zone = append(zone, n)
+					}
+				}
+			}
+			zones = append(zones, zone)
+		}
+	}
+	return zones
+}
@@ -25,6 +25,11 @@ func ProtocolPlayer(m *WorldMap) string {
 	sb.WriteString("START PLAYER\n")
 
 	for _, player := range m.Players() {
+		// elimination: dead ships are removed
+		if m.Elimination() && !player.IsAlive() {
+			continue
+		}
+
 		sb.WriteString("PlayerID:")
 		sb.WriteString(fmt.Sprintf("%d", player.PlayerID()))
 
@@ -57,6 +62,27 @@ func ProtocolPlayer(m *WorldMap) string {
 		sb.WriteString("|IsAlive:")
 		sb.WriteString(fmt.Sprintf("%v", player.IsAlive()))
 
+		sb.WriteString("|Shield:")
+		sb.WriteString(fmt.Sprintf("%d", player.Shield()))
+
+		sb.WriteString("|Mass:")
+		sb.WriteString(fmt.Sprintf("%.6f", player.Mass()))
+
+		sb.WriteString("|Team:")
+		sb.WriteString(player.Team())
+
+		sb.WriteString("|Class:")
+		sb.WriteString(player.Class().Name)
+
+		sb.WriteString("|Radius:")
+		sb.WriteString(fmt.Sprintf("%.6f", player.Radius()))
+
+		sb.WriteString("|DashCooldown:")
+		sb.WriteString(fmt.Sprintf("%d", player.DashCooldown()))
+
+		sb.WriteString("|BrakeCooldown:")
+		sb.WriteString(fmt.Sprintf("%d", player.BrakeCooldown()))
+
 		sb.WriteByte('\n')
 	}
 
@@ -73,6 +99,25 @@ func ProtocolStatus(m *WorldMap) string {
 	sb.WriteString(fmt.Sprintf("Endtime:%d\n", endtime))
 	sb.WriteString(fmt.Sprintf("MaxUpdateTime:%v\n", maxUpdateTime))
 	sb.WriteString(fmt.Sprintf("MaxPlayers:%d\n", m.MaxPlayers()))
+	if mode := m.GameMode(); mode != nil {
+		sb.WriteString(fmt.Sprintf("Mode:%s\n", mode.Name()))
+		sb.WriteString(mode.Status())
+	}
+	for _, t := range m.TeamScores() {
+		sb.WriteString(fmt.Sprintf("Team:%s=%d\n", t.Team, t.Score))
+	}
+	sb.WriteString(fmt.Sprintf("Overtime:%v\n", m.Overtime()))
+	sb.WriteString(fmt.Sprintf("GameOver:%v\n", m.GameOver()))
+	if m.GameOver() {
+		winner := -1
+		if m.Winner() != nil {
+			winner = m.Winner().PlayerID()
+		}
+		sb.WriteString(fmt.Sprintf("Winner:%d\n", winner))
+		if m.WinnerTeam() != "" {
+			sb.WriteString(fmt.Sprintf("WinnerTeam:%s\n", m.WinnerTeam()))
+		}
+	}
 
 	sb.WriteString("END STATUS\n")
 	return sb.String()
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"fmt"
+	"math"
+)
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// Elimination returns true if the elimination mode is active (see SetElimination).
+func (m *WorldMap) Elimination() bool {
+	return m.elimination
+}
+
+// GameOver returns true if the game has ended.
+// There are no more updates after that.
+func (m *WorldMap) GameOver() bool {
+	return m.gameOver
+}
+
+// WinnerTeam returns the team of the winner (see Winner).
+// In team games, the team with the highest aggregated score (see TeamScores) wins.
+// Games without teams return an empty string.
+func (m *WorldMap) WinnerTeam() string {
+	return m.winnerTeam
+}
+
+// Winner returns the winner of the game or nil.
+// The winner is set when the game is over (see GameOver).
+// There is no winner if the game is not over yet or the game ended in a tie.
+func (m *WorldMap) Winner() *Ship {
+	return m.winner
+}
+
+//--------  Setter  --------------------------------------------------------------------------------------------------//
+
+// SetEndtime sets the last iteration of the game (0 = no end time).
+func (m *WorldMap) SetEndtime(endtime uint64) {
+	if endtime <= 0 {
+		endtime = math.MaxUint64
+	}
+	m.endtime = endtime
+}
+
+// SetElimination enable or disable the elimination mode.
+// In elimination mode, dead ships are removed from the PLAYER block
+// and the game ends when only one ship (or team) remains (last ship standing).
+func (m *WorldMap) SetElimination(e bool) {
+	m.elimination = e
+}
+
+//--------  Update  --------------------------------------------------------------------------------------------------//
+
+// updateResult checks whether the game is over and determines the winner.
+// This function is called from WorldMap.Update().
+func (m *WorldMap) updateResult() {
+
+	// elimination: last ship (or team) standing
+	if m.elimination && len(m.players) >= 2 {
+		alive := make([]*Ship, 0, len(m.players))
+		sides := make(map[string]bool) // teams and ships without team
+		for _, p := range m.players {
+			if p.IsAlive() {
+				alive = append(alive, p)
+				sides[side(p)] = true
+			}
+		}
+		if len(sides) == 1 {
+			winner := leader(alive)
+			if winner == nil {
+				winner = alive[0] // tie within the winning team
+			}
+			m.endGame(winner)
+			return
+		}
+		if len(sides) == 0 {
+			winner := m.leader() // all died at the same time
+			if winner == nil {
+				winner = m.lastResort()
+			}
+			m.endGame(winner)
+			return
+		}
+	}
+
+	// end time: the highest score wins
+	// tied scores are decided by the tie-breaker (see SetTieBreak)
+	if m.iteration >= m.endtime {
+		if winner := m.leader(); winner != nil {
+			m.endGame(winner)
+		} else if winner, over := m.updateTieBreak(); over {
+			m.endGame(winner)
+		}
+	}
+}
+
+// endGame stops the game and sets the winner (can be nil).
+func (m *WorldMap) endGame(winner *Ship) {
+	m.gameOver = true
+	m.winner = winner
+	if winner != nil {
+		m.winnerTeam = winner.Team()
+	}
+
+	if winner == nil {
+		fmt.Println("GAME OVER: no winner")
+	} else if winner.Team() != "" {
+		fmt.Printf("GAME OVER: team %s wins (best ship %s with %d points)\n", winner.Team(), winner.Name(), winner.Score())
+	} else {
+		fmt.Printf("GAME OVER: %s wins with %d points\n", winner.Name(), winner.Score())
+	}
+}
+
+// leader returns the ship with the highest score.
+// In team games, the best ship of the team with the highest score is returned.
+// If several ships (or teams) share the highest score, nil is returned.
+func (m *WorldMap) leader() *Ship {
+	teams := m.TeamScores()
+	if len(teams) == 0 {
+		return leader(m.players)
+	}
+
+	// best team
+	best := teams[0]
+	tie := false
+	for _, t := range teams[1:] {
+		if t.Score > best.Score {
+			best = t
+			tie = false
+		} else if t.Score == best.Score {
+			tie = true
+		}
+	}
+	if tie {
+		return nil
+	}
+
+	// best ship of this team
+	members := make([]*Ship, 0, len(m.players))
+	for _, p := range m.players {
+		if p.Team() == best.Team {
+			members = append(members, p)
+		}
+	}
+	if l := leader(members); l != nil {
+		return l
+	}
+	return members[0] // tie within the winning team
+}
+
+// leader returns the ship with the highest score.
+// If several ships share the highest score, nil is returned.
+func leader(ships []*Ship) *Ship {
+	var best *Ship
+	tie := false
+	for _, p := range ships {
+		if best == nil || p.Score() > best.Score() {
+			best = p
+			tie = false
+		} else if p.Score() == best.Score() {
+			tie = true
+		}
+	}
+	if tie {
+		return nil
+	}
+	return best
+}
+
+// side returns the team of a ship or a unique key for ships without team.
+func side(s *Ship) string {
+	if s.Team() != "" {
+		return "team:" + s.Team()
+	}
+	return fmt.Sprintf("ship:%d", s.PlayerID())
+}
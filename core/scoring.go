@@ -0,0 +1,149 @@
+package core
+
+import (
+	"math"
+)
+
+// EventType is the type of a game event (see Event).
+type EventType int
+
+// Game events that change the score (see ScoringRules)
+const (
+	EventBump      EventType = iota // Ship bumped Other (Ship is the faster ship)
+	EventKnockOut                   // Ship was the last collider of Other, which fell into the void
+	EventFall                       // Ship fell into the void
+	EventPickup                     // Ship collected the Cell (CellType: Star, Anti, Shield or Mass)
+	EventWallHit                    // Ship crashed into a Blocked Cell
+	EventTick                       // Ship is alive (once per tick)
+	EventObjective                  // Ship reached a goal of the game mode (CellType: Hill or Goal, see GameMode)
+)
+
+// String returns the event type name (e.g. 'bump').
+func (t EventType) String() string {
+	switch t {
+	case EventBump:
+		return "bump"
+	case EventKnockOut:
+		return "knock-out"
+	case EventFall:
+		return "fall"
+	case EventPickup:
+		return "pickup"
+	case EventWallHit:
+		return "wall-hit"
+	case EventTick:
+		return "tick"
+	case EventObjective:
+		return "objective"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a game event that can change the score of one or two ships.
+type Event struct {
+	Type      EventType
+	Iteration uint64 // world iteration of the event
+	Ship      *Ship  // the ship that caused the event
+	Other     *Ship  // optional: the other ship (bump, knock-out)
+	Cell      *Cell  // optional: the cell (pickup, wall hit, objective)
+	CellType  byte   // optional: the cell type at the time of the event (the cell may have changed since)
+	Speed     float64
+	Points    int // optional: the points suggested by the game mode (objective)
+
+	Delta      int // score change of Ship (set by the scoring rules, see OnEvent)
+	OtherDelta int // score change of Other (set by the scoring rules, see OnEvent)
+}
+
+// ScoringRules decides the score changes of all game events.
+// (see WorldMap.SetScoring and DefaultScoring)
+type ScoringRules interface {
+	// Score returns the score deltas of the event for Event.Ship and Event.Other.
+	// The delta of Other is ignored if the event has no other ship.
+	Score(m *WorldMap, e Event) (ship, other int)
+}
+
+// interface check: ScoringRules
+var _ ScoringRules = DefaultScoring{}
+
+// DefaultScoring are the standard scoring rules of the game.
+//
+//	bump:      +5 for the faster ship, -5 for the slower ship (only above speed 7)
+//	knock-out: +50
+//	fall:      -30
+//	pickup:    +50 for a star, -30 for an anti-star
+//	wall hit:  -speed*0.3
+//	objective: the points of the game mode
+type DefaultScoring struct{}
+
+// Score returns the score deltas of the standard rules.
+func (DefaultScoring) Score(_ *WorldMap, e Event) (ship, other int) {
+	switch e.Type {
+	case EventBump:
+		if e.Speed > 7 {
+			return 5, -5
+		}
+	case EventKnockOut:
+		return 50, 0
+	case EventFall:
+		return -30, 0
+	case EventPickup:
+		switch e.CellType {
+		case Star:
+			return 50, 0
+		case Anti:
+			return -30, 0
+		}
+	case EventWallHit:
+		return -int(math.RoundToEven(e.Speed * 0.3)), 0
+	case EventObjective:
+		return e.Points, 0
+	}
+	return 0, 0
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// Scoring returns the active scoring rules (see SetScoring).
+func (m *WorldMap) Scoring() ScoringRules {
+	return m.scoring
+}
+
+//--------  Setter  --------------------------------------------------------------------------------------------------//
+
+// SetScoring replaces the scoring rules (nil restores DefaultScoring).
+// The friendly fire setting is applied on top of any rules (see SetFriendlyFire).
+func (m *WorldMap) SetScoring(r ScoringRules) {
+	if r == nil {
+		r = DefaultScoring{}
+	}
+	m.scoring = r
+}
+
+// OnEvent registers a listener that is called for each game event (e.g. kill feed, sound or particles).
+// The listener is called in the world update after the score has been changed.
+// Events between teammates without friendly fire are passed on too (without points).
+func (m *WorldMap) OnEvent(f func(e Event)) {
+	m.listeners = append(m.listeners, f)
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+// emit scores a game event, applies the score deltas and calls the listeners (see OnEvent).
+// Without friendly fire, events between teammates give no points.
+func (m *WorldMap) emit(e Event) {
+	e.Iteration = m.iteration
+	if e.Other == nil || m.friendlyFire || !e.Ship.IsTeammate(e.Other) {
+		e.Delta, e.OtherDelta = m.scoring.Score(m, e)
+		e.Ship.score += e.Delta
+		if e.Other != nil {
+			e.Other.score += e.OtherDelta
+		} else {
+			e.OtherDelta = 0
+		}
+	}
+
+	for _, f := range m.listeners {
+		f(e)
+	}
+}
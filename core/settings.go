@@ -0,0 +1,158 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HeaderEnd separates the optional map header from the grid.
+const HeaderEnd = "---"
+
+// HeaderKeys all supported header settings (see NewWorldMap)
+var HeaderKeys = []string{"name", "author", "description", "players", "endtime", "elimination", "friendly-fire",
+	"tiebreak", "mode", "bases", "event", "stars.cooldown", "stars.random", "stars.max", "stars.waves"}
+
+// setting is a single 'key: value' line of the map header.
+type setting struct {
+	key   string
+	value string
+	line  int
+}
+
+// cutHeader separates the optional header from the grid of a map text.
+// The header is placed before the line '---'.
+// Map texts without header return an empty header and the unchanged text.
+func cutHeader(s string) (header, grid string) {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if strings.TrimSpace(l) == HeaderEnd {
+			return strings.Join(lines[:i], "\n"), strings.Join(lines[i+1:], "\n")
+		}
+	}
+	return "", s // no header
+}
+
+// parseHeader parses the header settings (see cutHeader).
+// The header contains one 'key: value' setting per line.
+// Empty lines and lines starting with '//' are ignored.
+// Settings with unknown keys (see HeaderKeys) are returned separately.
+func parseHeader(header string) (settings, unknown []setting, err error) {
+	if header == "" {
+		return nil, nil, nil // no header
+	}
+
+	lines := strings.Split(header, "\n")
+	settings = make([]setting, 0, len(lines))
+	for i, l := range lines {
+		l = strings.TrimSpace(l)
+		if l == "" || strings.HasPrefix(l, "//") {
+			continue
+		}
+		kv := strings.SplitN(l, ":", 2)
+		if len(kv) != 2 {
+			return nil, nil, fmt.Errorf("invalid header: missing ':' in line %d", i+1)
+		}
+		st := setting{
+			key:   strings.ToLower(strings.TrimSpace(kv[0])),
+			value: strings.TrimSpace(kv[1]),
+			line:  i + 1,
+		}
+		if knownKey(st.key) {
+			settings = append(settings, st)
+		} else {
+			unknown = append(unknown, st)
+		}
+	}
+	return settings, unknown, nil
+}
+
+// knownKey returns true if the header setting is supported (see HeaderKeys).
+func knownKey(key string) bool {
+	for _, k := range HeaderKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// applySetting configures the world with a header setting (see HeaderKeys).
+func (m *WorldMap) applySetting(st setting) error {
+	var err error
+
+	switch st.key {
+	case "name":
+		m.info.Name = st.value
+	case "author":
+		m.info.Author = st.value
+	case "description":
+		m.info.Description = st.value
+	case "players":
+		m.info.Players, err = strconv.Atoi(st.value)
+		if err == nil && m.info.Players > m.MaxPlayers() {
+			fmt.Printf("err: map header: players %d is more than the spawn count %d\n", m.info.Players, m.MaxPlayers())
+		}
+	case "endtime":
+		m.info.Endtime, err = strconv.ParseUint(st.value, 10, 64)
+		if err == nil {
+			m.SetEndtime(m.info.Endtime)
+		}
+	case "elimination":
+		m.elimination, err = strconv.ParseBool(st.value)
+	case "friendly-fire":
+		m.friendlyFire, err = strconv.ParseBool(st.value)
+	case "tiebreak":
+		m.tieBreak, err = ParseTieBreak(st.value)
+	case "mode":
+		m.gameMode, err = NewGameMode(st.value)
+	case "bases":
+		m.info.Bases = parseList(st.value)
+	case "event":
+		var e MapEvent
+		if e, err = ParseMapEvent(st.value); err == nil {
+			err = m.AddMapEvent(e)
+		}
+	case "stars.cooldown":
+		m.starRules.Cooldown, err = strconv.Atoi(st.value)
+	case "stars.random":
+		m.starRules.Random, err = strconv.Atoi(st.value)
+	case "stars.max":
+		m.starRules.Max, err = strconv.Atoi(st.value)
+	case "stars.waves":
+		m.starRules.Waves, err = parseUintList(st.value)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid header: %s in line %d: %v", st.key, st.line, err)
+	}
+	return nil
+}
+
+// parseList parses a comma separated list of names (e.g. 'red, blue').
+func parseList(s string) []string {
+	list := make([]string, 0)
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// parseUintList parses a comma separated list of numbers (e.g. '600, 1200').
+func parseUintList(s string) ([]uint64, error) {
+	list := make([]uint64, 0)
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		i, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, i)
+	}
+	return list, nil
+}
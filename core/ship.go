@@ -1,8 +1,9 @@
 package core
 
 import (
+	"image/color"
 	"io"
-	"math"
+	"sync"
 )
 
 // Ship represents a player ship.
@@ -12,44 +13,84 @@ type Ship struct {
 	playerID int
 	name     string
 	color    string
+	rgb      color.RGBA
+	team     string        // optional
+	class    ShipClass     // see ShipClasses
 	remoteRW io.ReadWriter // optional
 
+	controller Controller // optional (see SetController)
+
+	drawMux sync.Mutex // the drawing is set by the command listener (see Draw)
+	drawing []Shape    // shown debug shapes (see SetDrawing)
+	drawBuf []Shape    // collected shapes of the DRAW command
+
 	position     *Vector
 	velocity     *Vector
 	acceleration *Vector
 	score        int
-
-	factorAccel   float64
-	factorVeloc   float64
-	factorRollRes float64
-	factorBoost   float64
-	factorSlow    float64
+	spawns       int  // number of (re)spawns
+	stars        int  // number of collected stars
+	shield       int  // bumps that are ignored (see Shield cell)
+	massTicks    int  // remaining ticks with increased mass (see Mass cell)
+	dashTicks    int  // remaining cooldown of the dash (see Dash)
+	brakeTicks   int  // remaining cooldown of the brake (see Brake)
+	dashReq      bool // dash is executed with the next update
+	brakeReq     bool // brake is executed with the next update
+
+	factorAccel    float64
+	factorVeloc    float64
+	factorRollRes  float64
+	factorBoost    float64
+	factorSlow     float64
+	factorMass     float64
+	factorConveyor float64
+	massDuration   int
+	factorDash     float64
+	factorBrake    float64
+	dashCooldown   int
+	brakeCooldown  int
 
 	lastCollider *Ship
+	lastCell     *Cell
 	oldPosition  *Vector
 }
 
 // NewShip create a new ship without spawning.
+// The physical attributes are taken from the ship class (see ShipClasses).
 // (used by WorldMap.AddPlayer)
-func NewShip(world *WorldMap, playerID int, name, color string, remote io.ReadWriter) *Ship {
+func NewShip(world *WorldMap, playerID int, name, colorName, team string, class ShipClass, remote io.ReadWriter) *Ship {
+	rgb, _ := ParseColor(colorName) // validated by WorldMap.AddPlayer
 
 	ship := &Ship{
 		world:         world,
 		playerID:      playerID,
 		name:          name,
-		color:         color,
+		color:         colorName,
+		rgb:           rgb,
+		team:          team,
+		class:         class,
 		remoteRW:      remote,
 		position:      new(Vector),
 		velocity:      new(Vector),
 		acceleration:  new(Vector),
-		score:         100,   // [default 100] start health points of the ship
-		factorAccel:   0.15,  // [default 0.05] how fast is acceleration converted to velocity
-		factorVeloc:   0.15,  // [default 0.1] how much does velocity change the position per tick
-		factorRollRes: 0.985, // [default 0.97] rolling resistance limit the max. speed
-		factorBoost:   1.05,  // [default 1.1] effect of the boost cell
-		factorSlow:    0.95,  // [default 0.9] effect of the slow cell
-		lastCollider:  nil,
-		oldPosition:   new(Vector),
+		score:         100,         // [default 100] start health points of the ship
+		factorAccel:   class.Accel, // [normal 0.15] how fast is acceleration converted to velocity
+		factorVeloc:   0.15,        // [default 0.1] how much does velocity change the position per tick
+		factorRollRes: class.Grip,  // [normal 0.985] rolling resistance limit the max. speed
+		factorBoost:   1.05,        // [default 1.1] effect of the boost cell
+		factorSlow:    0.95,        // [default 0.9] effect of the slow cell
+
+		factorMass:     2.0, // mass multiplier of the mass cell
+		factorConveyor: 0.3, // push of the conveyor cells per tick
+		massDuration:   600, // ticks with increased mass (~10 sec)
+		factorDash:     6.0, // velocity impulse of the dash
+		factorBrake:    0.2, // remaining velocity after the brake
+		dashCooldown:   180, // ticks between two dashes (~3 sec)
+		brakeCooldown:  240, // ticks between two brakes (~4 sec)
+
+		lastCollider: nil,
+		lastCell:     nil,
+		oldPosition:  new(Vector),
 	}
 
 	return ship
@@ -67,12 +108,40 @@ func (s *Ship) Name() string {
 	return s.name
 }
 
-// Color returns the ship color.
-// (see WorldMap.AddPlayer)
+// Color returns the ship color (a named color or a hex color like '#ff8800').
+// (see WorldMap.AddPlayer and RGB)
 func (s *Ship) Color() string {
 	return s.color
 }
 
+// RGB returns the RGB value of the ship color (see ParseColor).
+func (s *Ship) RGB() color.RGBA {
+	return s.rgb
+}
+
+// Team returns the team name of this ship.
+// Ships without a team return an empty string.
+// (see WorldMap.AddPlayer)
+func (s *Ship) Team() string {
+	return s.team
+}
+
+// Class returns the ship class (see ShipClasses).
+func (s *Ship) Class() ShipClass {
+	return s.class
+}
+
+// Radius returns the collision radius of the ship (see ShipClass).
+func (s *Ship) Radius() float64 {
+	return s.class.Radius
+}
+
+// IsTeammate returns true if both ships are in the same team.
+// Ships without a team have no teammates.
+func (s *Ship) IsTeammate(o *Ship) bool {
+	return s.team != "" && s.team == o.team && s.playerID != o.playerID
+}
+
 // Remote If set, then this ship is controlled remotely
 // and the world status is sent back via this channel continuously.
 func (s *Ship) Remote() io.ReadWriter {
@@ -111,7 +180,49 @@ func (s *Ship) Angle() float64 {
 // TouchingCells returns all cells touched by a ship.
 // (see WorldMap.TouchingCells)
 func (s *Ship) TouchingCells() []*Cell {
-	return s.world.TouchingCells(s.position)
+	return s.world.touchingCells(s.position, s.Radius())
+}
+
+// Spawns returns how often the ship has been (re)spawned.
+func (s *Ship) Spawns() int {
+	return s.spawns
+}
+
+// Stars returns the number of collected stars (see Star cell).
+func (s *Ship) Stars() int {
+	return s.stars
+}
+
+// Shield returns the number of bumps the ship will ignore (see Shield cell).
+func (s *Ship) Shield() int {
+	return s.shield
+}
+
+// Mass returns the current ship mass (default 1.0).
+// The mass is increased for a while by the Mass cell.
+// Heavier ships push other ships harder.
+func (s *Ship) Mass() float64 {
+	mass := s.class.Mass
+	if s.massTicks > 0 {
+		mass *= s.factorMass
+	}
+	return mass
+}
+
+// DashCooldown returns the remaining ticks until the next dash is possible (0 = ready).
+func (s *Ship) DashCooldown() int {
+	return s.dashTicks
+}
+
+// BrakeCooldown returns the remaining ticks until the next brake is possible (0 = ready).
+func (s *Ship) BrakeCooldown() int {
+	return s.brakeTicks
+}
+
+// LastCollider returns the ship that collided last with this ship (or nil).
+// It gets the knock-out points if this ship falls into the void (see EventKnockOut).
+func (s *Ship) LastCollider() *Ship {
+	return s.lastCollider
 }
 
 // IsAlive return true if the ship score is not 0.
@@ -134,13 +245,38 @@ func (s *Ship) Move(acceleration *Vector) {
 	s.acceleration = acceleration
 }
 
+// Dash requests a short speed impulse in the direction of the acceleration
+// (or the current direction if there is no acceleration).
+// The dash is executed with the next update. Returns false during the cooldown.
+func (s *Ship) Dash() bool {
+	if s.dashTicks > 0 {
+		return false
+	}
+	s.dashReq = true
+	return true
+}
+
+// Brake requests an emergency brake that removes most of the velocity.
+// The brake is executed with the next update. Returns false during the cooldown.
+func (s *Ship) Brake() bool {
+	if s.brakeTicks > 0 {
+		return false
+	}
+	s.brakeReq = true
+	return true
+}
+
 // Spawn set the ship to a random spawner (see WorldMap.FreeSpawn).
-// velocity and acceleration are reset.
+// velocity, acceleration and power-ups are reset.
 func (s *Ship) Spawn() {
 	spawn := s.world.FreeSpawn()
 	s.velocity = new(Vector)
 	s.acceleration = new(Vector)
 	s.position = spawn.Clone()
+	s.spawns++
+	s.shield = 0
+	s.massTicks = 0
+	s.lastCell = nil
 }
 
 // Collide returns true if there is a collision with the given ship.
@@ -148,7 +284,7 @@ func (s *Ship) Collide(o *Ship) bool {
 	tmp := s.position.Clone()
 	tmp.Add(o.position, -1)
 	l := tmp.Length()
-	return l < 2*CellRadius
+	return l < s.Radius()+o.Radius()
 }
 
 //--------  UPDATE  --------------------------------------------------------------------------------------------------//
@@ -168,11 +304,44 @@ func (s *Ship) Update() {
 		return // EXIT
 	}
 
+	// MASS: The increased mass wears off over time.
+	//-----------------------------------------------------
+	if s.massTicks > 0 {
+		s.massTicks--
+	}
+
 	// SPEED: Acceleration is converted to velocity.
 	//-----------------------------------------------------
 	s.velocity.Add(s.acceleration, s.factorAccel)
 	s.velocity.Multi(s.factorRollRes)
 
+	// ABILITIES: Dash and brake change the velocity at once.
+	// Both are limited by a cooldown.
+	//-----------------------------------------------------
+	if s.dashTicks > 0 {
+		s.dashTicks--
+	}
+	if s.brakeTicks > 0 {
+		s.brakeTicks--
+	}
+	if s.dashReq {
+		s.dashReq = false
+		dir := s.acceleration.Clone()
+		if dir.Length() == 0 {
+			dir = s.velocity.Clone()
+		}
+		if dir.Length() > 0 {
+			dir.Normalize()
+			s.velocity.Add(dir, s.factorDash)
+			s.dashTicks = s.dashCooldown
+		}
+	}
+	if s.brakeReq {
+		s.brakeReq = false
+		s.velocity.Multi(s.factorBrake)
+		s.brakeTicks = s.brakeCooldown
+	}
+
 	// POSITION: Velocity moves the ship (new position).
 	//-----------------------------------------------------
 	s.oldPosition = s.position.Clone()
@@ -182,6 +351,7 @@ func (s *Ship) Update() {
 	// OTHER SHIPS: Colliding with other ships damages the slower ship
 	// and heals the faster ship. The collision changes the ship's
 	// course and reset the acceleration.
+	// The speed is weighted with the ship mass (momentum).
 	//-----------------------------------------------------
 	for _, o := range s.world.Players() {
 		// check collisions
@@ -189,47 +359,65 @@ func (s *Ship) Update() {
 			continue
 		}
 
-		// set last collider
-		s.lastCollider = o
-		o.lastCollider = s
-
-		// reset acceleration
-		s.acceleration = new(Vector)
-		o.acceleration = new(Vector)
-
 		// winner / loser
 		winner := o
 		loser := s
-		if s.velocity.Length() > o.velocity.Length() {
+		if s.Mass()*s.velocity.Length() > o.Mass()*o.velocity.Length() {
 			winner = s
 			loser = o
 		}
 
-		// score
-		if winner.velocity.Length() > 7 {
-			winner.score += 5
-			loser.score -= 5
+		// shield: the loser ignores this bump
+		if loser.shield > 0 {
+			loser.shield--
+			winner.acceleration = new(Vector)
+			winner.position = winner.oldPosition.Clone()
+			winner.velocity.Add(winner.velocity, -1.5)
+			continue
 		}
 
-		// loser get off the road
-		loser.velocity.Add(winner.velocity.Clone(), 1.5)
+		// set last collider
+		s.lastCollider = o
+		o.lastCollider = s
+
+		// reset acceleration
+		s.acceleration = new(Vector)
+		o.acceleration = new(Vector)
+
+		// score (see ScoringRules)
+		s.world.emit(Event{Type: EventBump, Ship: winner, Other: loser, Speed: winner.velocity.Length()})
+
+		// loser get off the road (heavy ships push harder)
+		loser.velocity.Add(winner.velocity.Clone(), 1.5*winner.Mass()/loser.Mass())
 
 		// winner bounce back
 		winner.position = winner.oldPosition.Clone()
 		winner.velocity.Add(winner.velocity, -1.5)
 	}
 
+	// CRUMBLE cell interaction.
+	// The cell collapses into the void after the ship has left it.
+	//-----------------------------------------------------
+	prevCell := s.lastCell
+	if prevCell != nil && prevCell != currentCell && prevCell.Type() == Crumble {
+		prevCell.SetType(None)
+	}
+	s.lastCell = currentCell
+
 	// NONE cell interaction (die).
 	// A ship loses points if it falls into the void and respawn.
 	// If there was previously a collision with another ship,
-	// the other ship gets points.
+	// the other ship gets points (teammates only with friendly fire).
 	//-----------------------------------------------------
 	if currentCell.Type() == None {
 		if s.lastCollider != nil {
-			s.lastCollider.score += 50
+			s.world.emit(Event{Type: EventKnockOut, Ship: s.lastCollider, Other: s, Cell: currentCell})
 			s.lastCollider = nil
 		}
-		s.score -= 30
+		s.world.emit(Event{Type: EventFall, Ship: s, Cell: currentCell, Speed: s.velocity.Length()})
+		if s.world.suddenDeath() {
+			s.score = 0 // no respawn in sudden death (see TieBreakSuddenDeath)
+		}
 		if s.IsAlive() {
 			s.Spawn()
 			return //EXIT
@@ -240,7 +428,8 @@ func (s *Ship) Update() {
 	// The star is collected when touched and gives points.
 	//-----------------------------------------------------
 	if currentCell.Type() == Star {
-		s.score += 50
+		s.stars++
+		s.world.emit(Event{Type: EventPickup, Ship: s, Cell: currentCell, CellType: Star})
 		currentCell.SetType(Tile) // remove star
 	}
 
@@ -248,10 +437,46 @@ func (s *Ship) Update() {
 	// The star is collected when touched and removes points.
 	//-----------------------------------------------------
 	if currentCell.Type() == Anti {
-		s.score -= 30
+		s.world.emit(Event{Type: EventPickup, Ship: s, Cell: currentCell, CellType: Anti})
 		currentCell.SetType(Tile) // remove anti star
 	}
 
+	// SHIELD cell interaction (good).
+	// The shield is collected when touched and protects against the next bump.
+	//-----------------------------------------------------
+	if currentCell.Type() == Shield {
+		s.shield = 1
+		s.world.emit(Event{Type: EventPickup, Ship: s, Cell: currentCell, CellType: Shield})
+		currentCell.SetType(Tile) // remove shield
+	}
+
+	// MASS cell interaction (good).
+	// The mass is collected when touched and makes the ship heavier for a while.
+	//-----------------------------------------------------
+	if currentCell.Type() == Mass {
+		s.massTicks = s.massDuration
+		s.world.emit(Event{Type: EventPickup, Ship: s, Cell: currentCell, CellType: Mass})
+		currentCell.SetType(Tile) // remove mass
+	}
+
+	// TELEPORT cell interaction.
+	// Entering a teleport cell moves the ship to the paired teleport cell.
+	// The velocity is kept.
+	//-----------------------------------------------------
+	if currentCell.Type() == Teleport && currentCell != prevCell {
+		if target := s.world.Teleport(currentCell); target != nil {
+			s.position = target.Center()
+			s.oldPosition = target.Center()
+			s.lastCell = target
+			currentCell = target
+		}
+	}
+
+	// CONVEYOR cell interaction.
+	// The conveyor pushes the ship in one direction.
+	//-----------------------------------------------------
+	s.velocity.Add(currentCell.Conveyor(), s.factorConveyor)
+
 	// NEAR CELLs: interaction with BLOCK, BOOST and SLOW.
 	// These cells affect the ship as long as they are touched.
 	//-----------------------------------------------------
@@ -259,9 +484,8 @@ func (s *Ship) Update() {
 
 		// crash and rebound
 		if c.Type() == Blocked {
-			// calc damage
-			damage := math.RoundToEven(s.velocity.Length() * 0.3)
-			s.score -= int(damage)
+			// damage (see ScoringRules)
+			s.world.emit(Event{Type: EventWallHit, Ship: s, Cell: c, CellType: Blocked, Speed: s.velocity.Length()})
 			// bounce back
 			s.position = s.oldPosition.Clone()
 			s.velocity.Add(s.velocity, -1.5)
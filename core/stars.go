@@ -0,0 +1,136 @@
+package core
+
+import (
+	"math/rand"
+)
+
+// StarRules defines if and how stars respawn.
+// All rules are optional and can be combined.
+// The rules are set in the map header (see NewWorldMap) or with WorldMap.SetStarRules.
+//
+//	stars.cooldown: 600       // a collected star respawns at its cell after 600 ticks
+//	stars.random: 300         // every 300 ticks a star is placed on a random free tile
+//	stars.max: 10             // random stars are only placed if there are less than 10 stars
+//	stars.waves: 3600, 7200   // all collected stars respawn at these iterations
+type StarRules struct {
+	Cooldown int      // ticks until a collected star respawns at its cell (0 = off)
+	Random   int      // ticks between two stars on random free tiles (0 = off)
+	Max      int      // max. stars on the map for random placement (0 = stars at game start)
+	Waves    []uint64 // iterations at which all collected stars respawn
+}
+
+// starState tracks the stars of the map (see StarRules).
+type starState struct {
+	origin    []*Cell          // star cells at game start
+	collected map[*Cell]uint64 // iteration when an origin star was collected
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// StarRules returns the star respawn rules of this world.
+func (m *WorldMap) StarRules() StarRules {
+	return m.starRules
+}
+
+// Stars returns all cells that currently contain a star.
+func (m *WorldMap) Stars() []*Cell {
+	list := make([]*Cell, 0, len(m.stars.origin))
+	for xCol := 0; xCol < m.xWidth; xCol++ {
+		for yRow := 0; yRow < m.yHeight; yRow++ {
+			if c := m.grid[xCol][yRow]; c.Type() == Star {
+				list = append(list, c)
+			}
+		}
+	}
+	return list
+}
+
+//--------  Setter  --------------------------------------------------------------------------------------------------//
+
+// SetStarRules change the star respawn rules of this world.
+func (m *WorldMap) SetStarRules(r StarRules) {
+	m.starRules = r
+}
+
+//--------  Update  --------------------------------------------------------------------------------------------------//
+
+// updateStars respawns stars according to the StarRules.
+// This function is called from WorldMap.Update().
+func (m *WorldMap) updateStars() {
+	r := m.starRules
+
+	// remember collected stars
+	for _, c := range m.stars.origin {
+		if _, ok := m.stars.collected[c]; !ok && c.Type() == Tile {
+			m.stars.collected[c] = m.iteration
+		}
+	}
+
+	// waves: all collected stars respawn
+	for _, w := range r.Waves {
+		if w == m.iteration {
+			for c := range m.stars.collected {
+				m.respawnStar(c)
+			}
+		}
+	}
+
+	// cooldown: a collected star respawns after a while
+	if r.Cooldown > 0 {
+		for c, it := range m.stars.collected {
+			if m.iteration-it >= uint64(r.Cooldown) {
+				m.respawnStar(c)
+			}
+		}
+	}
+
+	// random: a star is placed on a random free tile
+	if r.Random > 0 && m.iteration > 0 && m.iteration%uint64(r.Random) == 0 {
+		max := r.Max
+		if max <= 0 {
+			max = len(m.stars.origin)
+		}
+		if len(m.Stars()) < max {
+			free := make([]*Cell, 0, m.xWidth*m.yHeight)
+			for xCol := 0; xCol < m.xWidth; xCol++ {
+				for yRow := 0; yRow < m.yHeight; yRow++ {
+					if c := m.grid[xCol][yRow]; c.Type() == Tile && !m.isTouched(c) {
+						free = append(free, c)
+					}
+				}
+			}
+			if len(free) > 0 {
+				free[rand.Intn(len(free))].SetType(Star)
+				m.mapChanged = true
+			}
+		}
+	}
+}
+
+// respawnStar sets a collected star back to its cell.
+// The star does not respawn while a ship touches the cell
+// or if the cell is no longer a Tile (e.g. changed to None).
+func (m *WorldMap) respawnStar(c *Cell) {
+	if c.Type() != Tile {
+		delete(m.stars.collected, c) // cell has changed: forget this star
+		return
+	}
+	if m.isTouched(c) {
+		return // try again later
+	}
+	c.SetType(Star)
+	delete(m.stars.collected, c)
+	m.mapChanged = true
+}
+
+// isTouched returns true if a ship touches the given cell.
+func (m *WorldMap) isTouched(c *Cell) bool {
+	for _, p := range m.players {
+		for _, tc := range p.TouchingCells() {
+			if tc == c {
+				return true
+			}
+		}
+	}
+	return false
+}
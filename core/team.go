@@ -0,0 +1,46 @@
+package core
+
+import (
+	"sort"
+)
+
+// TeamScore is the aggregated score of all ships of a team.
+type TeamScore struct {
+	Team  string
+	Score int
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// FriendlyFire returns true if teammates get points for bumping each other (see SetFriendlyFire).
+func (m *WorldMap) FriendlyFire() bool {
+	return m.friendlyFire
+}
+
+// TeamScores returns the aggregated scores of all teams (sorted by team name).
+// Ships without a team are ignored.
+func (m *WorldMap) TeamScores() []TeamScore {
+	scores := make(map[string]int)
+	for _, p := range m.players {
+		if p.Team() != "" {
+			scores[p.Team()] += p.Score()
+		}
+	}
+
+	list := make([]TeamScore, 0, len(scores))
+	for t, s := range scores {
+		list = append(list, TeamScore{Team: t, Score: s})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Team < list[j].Team
+	})
+	return list
+}
+
+//--------  Setter  --------------------------------------------------------------------------------------------------//
+
+// SetFriendlyFire enable or disable the friendly fire (default: enabled).
+// Without friendly fire, bumping a teammate or knocking them out gives no points.
+func (m *WorldMap) SetFriendlyFire(f bool) {
+	m.friendlyFire = f
+}
@@ -0,0 +1,218 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// TieBreak decides the winner if the scores are tied at the end time.
+// (see WorldMap.SetTieBreak)
+type TieBreak int
+
+// Supported tie-breakers
+const (
+	TieBreakNone        TieBreak = iota // the game ends without a winner
+	TieBreakOvertime                    // the game continues until one ship (or team) leads
+	TieBreakSuddenDeath                 // overtime, the arena shrinks (see ShrinkArena) and falling ships are destroyed
+	TieBreakStars                       // the most collected stars win, then overtime
+)
+
+// shrinkInterval ticks between two shrinks in sudden death (~3 sec)
+const shrinkInterval = 180
+
+// TieBreaks all tie-breaker names (see ParseTieBreak)
+var TieBreaks = []string{"none", "overtime", "sudden-death", "stars"}
+
+// ParseTieBreak returns the tie-breaker by its name (see TieBreaks).
+func ParseTieBreak(name string) (TieBreak, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, n := range TieBreaks {
+		if n == name {
+			return TieBreak(i), nil
+		}
+	}
+	return TieBreakNone, fmt.Errorf("invalid tie-breaker '%s': use %s", name, strings.Join(TieBreaks, ", "))
+}
+
+// String returns the tie-breaker name (e.g. 'sudden-death').
+func (t TieBreak) String() string {
+	if t < 0 || int(t) >= len(TieBreaks) {
+		return "unknown"
+	}
+	return TieBreaks[t]
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// TieBreak returns the active tie-breaker (see SetTieBreak).
+func (m *WorldMap) TieBreak() TieBreak {
+	return m.tieBreak
+}
+
+// Overtime returns true if the end time is reached with a tied score
+// and the game continues (see SetTieBreak).
+func (m *WorldMap) Overtime() bool {
+	return m.overtime
+}
+
+//--------  Setter  --------------------------------------------------------------------------------------------------//
+
+// SetTieBreak sets the tie-breaker for tied scores at the end time (default: TieBreakNone).
+// In sudden death, the arena shrinks every 3 seconds (see ShrinkArena)
+// and ships that fall into the void are destroyed.
+func (m *WorldMap) SetTieBreak(t TieBreak) {
+	m.tieBreak = t
+}
+
+//--------  Update  --------------------------------------------------------------------------------------------------//
+
+// ShrinkArena converts the outer ring of the arena into void (None).
+// All cells next to the void or the map border are converted.
+// Ships on these cells fall down. Returns the number of converted cells.
+func (m *WorldMap) ShrinkArena() int {
+	edge := make([]*Cell, 0, 2*(m.xWidth+m.yHeight))
+	for xCol := 0; xCol < m.xWidth; xCol++ {
+		for yRow := 0; yRow < m.yHeight; yRow++ {
+			if c := m.grid[xCol][yRow]; c.Type() != None && m.isEdge(xCol, yRow) {
+				edge = append(edge, c)
+			}
+		}
+	}
+	for _, c := range edge {
+		c.SetType(None)
+	}
+	if len(edge) > 0 {
+		m.mapChanged = true
+	}
+	return len(edge)
+}
+
+// updateTieBreak applies the tie-breaker at the end time.
+// Returns the winner or nil if the game continues (overtime).
+// This function is called from WorldMap.updateResult().
+func (m *WorldMap) updateTieBreak() (winner *Ship, over bool) {
+	switch m.tieBreak {
+	case TieBreakNone:
+		return nil, true
+	case TieBreakStars:
+		if w := m.starLeader(); w != nil {
+			return w, true
+		}
+	}
+
+	// overtime
+	if !m.overtime {
+		m.overtime = true
+		fmt.Printf("OVERTIME: tie-breaker %s\n", m.tieBreak)
+	}
+
+	// sudden death: shrink the arena
+	if m.tieBreak == TieBreakSuddenDeath && m.iteration > m.endtime && (m.iteration-m.endtime)%shrinkInterval == 0 {
+		if m.ShrinkArena() == 0 {
+			return m.lastResort(), true // nothing left to shrink
+		}
+	}
+	return nil, false
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+// suddenDeath returns true during the overtime of the sudden death tie-breaker.
+func (m *WorldMap) suddenDeath() bool {
+	return m.overtime && m.tieBreak == TieBreakSuddenDeath
+}
+
+// isEdge returns true if the cell is on the map border or next to the void.
+func (m *WorldMap) isEdge(xCol, yRow int) bool {
+	for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+		x, y := xCol+d[0], yRow+d[1]
+		if x < 0 || y < 0 || x >= m.xWidth || y >= m.yHeight || m.grid[x][y].Type() == None {
+			return true
+		}
+	}
+	return false
+}
+
+// lastResort returns the winner of a tie that the tie-breaker could not decide (nil only without players).
+// Of the ships (or teams) with the highest score, the ship with the most collected stars wins,
+// then the ship with the fewest deaths (see Ship.Spawns) and then the ship with the lowest PlayerID.
+func (m *WorldMap) lastResort() *Ship {
+	teams := len(m.TeamScores()) > 0
+
+	// score per side
+	score := make(map[string]int)
+	for _, p := range m.players {
+		if !teams || p.Team() != "" {
+			score[side(p)] += p.Score()
+		}
+	}
+	max := math.MinInt32
+	for _, s := range score {
+		if s > max {
+			max = s
+		}
+	}
+
+	// best ship of the tied sides (players are ordered by PlayerID)
+	var best *Ship
+	for _, p := range m.players {
+		if (teams && p.Team() == "") || score[side(p)] != max {
+			continue
+		}
+		if best == nil || p.Stars() > best.Stars() || (p.Stars() == best.Stars() && p.Spawns() < best.Spawns()) {
+			best = p
+		}
+	}
+	return best
+}
+
+// starLeader returns the best ship of the tied ship (or team) with the most collected stars.
+// If the stars are tied too, nil is returned.
+func (m *WorldMap) starLeader() *Ship {
+	teams := len(m.TeamScores()) > 0
+
+	// score, stars and members per side
+	score := make(map[string]int)
+	stars := make(map[string]int)
+	members := make(map[string][]*Ship)
+	for _, p := range m.players {
+		if teams && p.Team() == "" {
+			continue // only teams compete in team games (see leader)
+		}
+		k := side(p)
+		score[k] += p.Score()
+		stars[k] += p.Stars()
+		members[k] = append(members[k], p)
+	}
+
+	// highest score
+	max := math.MinInt32
+	for _, s := range score {
+		if s > max {
+			max = s
+		}
+	}
+
+	// most stars of the tied sides
+	best := ""
+	tie := false
+	for k, s := range score {
+		if s != max {
+			continue
+		}
+		if best == "" || stars[k] > stars[best] {
+			best = k
+			tie = false
+		} else if stars[k] == stars[best] {
+			tie = true
+		}
+	}
+	if best == "" || tie {
+		return nil
+	}
+	if l := leader(members[best]); l != nil {
+		return l
+	}
+	return members[best][0] // tie within the winning team
+}
@@ -10,6 +10,7 @@ import (
 	"math/rand"
 	"net/textproto"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -19,16 +20,38 @@ import (
 // WorldMap represents the current world status
 // with the grid and all players.
 type WorldMap struct {
+	info MapInfo // metadata (see Info)
+
 	freeze        bool
 	iteration     uint64
 	endtime       uint64
 	maxUpdateTime time.Duration
+	updateTime    time.Duration // see UpdateTime
+
+	elimination  bool     // see SetElimination
+	friendlyFire bool     // see SetFriendlyFire
+	gameOver     bool     // see GameOver
+	winner       *Ship    // see Winner
+	winnerTeam   string   // see WinnerTeam
+	tieBreak     TieBreak // see SetTieBreak
+	overtime     bool     // see Overtime
 
 	xWidth  int       // grid size (width)
 	yHeight int       // grid size (height)
 	grid    [][]*Cell // grid (map)
 	spawns  []*Cell   // spawn cell list
 
+	teleports map[*Cell]*Cell // teleport pairs (see Teleport)
+
+	gameMode   GameMode          // optional (see SetGameMode)
+	scoring    ScoringRules      // see SetScoring
+	listeners  []func(Event)     // see OnEvent
+	onUpdate   []func(*WorldMap) // see OnUpdate
+	starRules  StarRules         // star respawn rules (see StarRules)
+	stars      starState         // star tracking (see StarRules)
+	mapEvents  []MapEvent        // scripted map changes (see MapEvent)
+	mapChanged bool              // the MAP block is sent with the next update
+
 	players []*Ship // all players (alive and dead)
 }
 
@@ -38,18 +61,30 @@ type WorldMap struct {
 // The chars are the columns, the lines are the rows of the grid.
 // All lines must contain the same number of characters.
 // Each line must end with the character '|'.
+// Teleport cells are paired in the order in which they appear in the text (see Teleport).
+//
+// An optional header can be placed before the grid. It ends with the line '---'
+// and contains one 'key: value' setting per line (e.g. 'stars.cooldown: 600', see StarRules).
+// Scripted map changes are defined with 'event' settings (see MapEvent).
+// The metadata (name, author, description, players) is available with Info.
+// The rules (endtime, elimination, friendly-fire, tiebreak, mode) override the defaults and the given endtime.
+// Maps without header use the defaults. Unknown settings are ignored (see MapInfo.Unknown).
+// The map is not printed on the console (see LoadWorldMap), so the linter can check maps silently.
 func NewWorldMap(b []byte, endtime uint64) (*WorldMap, error) {
 
-	// split lines
-	s := strings.ReplaceAll(string(b), "\r", "") // remove '\r'
-	s = strings.ReplaceAll(s, "|", "")           // remove '|'
-	lines := strings.Split(s, "\n")              // split lines ('\n')
+	// split header and lines
+	header, lines := SplitMap(b)
+	settings, unknown, err := parseHeader(header)
+	if err != nil {
+		return nil, err
+	}
 
 	// parse data
 	var xWidth int
 	var yHeight = len(lines)
 	var grid [][]*Cell
 	var spawns = make([]*Cell, 0)
+	var stars = make([]*Cell, 0)
 
 	for yRow, l := range lines {
 		// action for first line
@@ -73,6 +108,10 @@ func NewWorldMap(b []byte, endtime uint64) (*WorldMap, error) {
 			if c.Type() == Spawn {
 				spawns = append(spawns, c)
 			}
+			// save star positions
+			if c.Type() == Star {
+				stars = append(stars, c)
+			}
 		}
 	}
 
@@ -84,6 +123,8 @@ func NewWorldMap(b []byte, endtime uint64) (*WorldMap, error) {
 	// build map
 	wm := &WorldMap{
 		freeze:        false,
+		friendlyFire:  true,
+		scoring:       DefaultScoring{},
 		iteration:     0,
 		endtime:       endtime,
 		maxUpdateTime: 0,
@@ -92,10 +133,24 @@ func NewWorldMap(b []byte, endtime uint64) (*WorldMap, error) {
 		grid:          grid,
 		spawns:        spawns,
 		players:       make([]*Ship, 0, len(spawns)),
+		stars: starState{
+			origin:    stars,
+			collected: make(map[*Cell]uint64),
+		},
+	}
+	wm.linkTeleports()
+
+	// apply header settings
+	for _, st := range settings {
+		if err := wm.applySetting(st); err != nil {
+			return nil, err
+		}
+	}
+	for _, st := range unknown {
+		wm.info.Unknown = append(wm.info.Unknown, st.key)
 	}
 
 	// return
-	wm.Print()
 	return wm, nil
 }
 
@@ -106,32 +161,82 @@ func NewWorldMap(b []byte, endtime uint64) (*WorldMap, error) {
 // The chars are the columns, the lines are the rows of the grid.
 // All lines must contain the same number of characters.
 // Each line must end with the character '|'.
+// The optional map header is described in NewWorldMap.
+// The loaded map is printed on the console (see Print).
 func LoadWorldMap(mapName string, endtime uint64) (*WorldMap, error) {
 
-	// search map file
+	// read file
+	mapName = MapPath(mapName)
+	b, err := os.ReadFile(mapName)
+	if err != nil {
+		return nil, err
+	}
+
+	// return new world
+	wm, err := NewWorldMap(b, endtime)
+	if err != nil {
+		return nil, err
+	}
+	if wm.info.Name == "" {
+		wm.info.Name = strings.TrimSuffix(filepath.Base(mapName), filepath.Ext(mapName))
+	}
+	for _, k := range wm.info.Unknown {
+		fmt.Printf("WARNING: unknown header setting '%s' (ignored)\n", k)
+	}
+	wm.Print()
+	return wm, nil
+}
+
+// MapPath returns the path of the map file '{mapName}.txt' (see LoadWorldMap).
+// If the file is not found, '{mapName}.txt' is returned.
+func MapPath(mapName string) string {
 	if !strings.HasSuffix(strings.ToLower(mapName), ".txt") {
 		mapName += ".txt"
 	}
 	paths := []string{mapName, "maps/" + mapName, "../maps/" + mapName, "../../maps/" + mapName}
 	for _, p := range paths {
-		_, e := os.Stat(p)
-		if e == nil {
-			mapName = p
-			break
+		if _, e := os.Stat(p); e == nil {
+			return p
 		}
 	}
+	return mapName
+}
 
-	// read file
-	b, err := os.ReadFile(mapName)
-	if err != nil {
-		return nil, err
+// MapNames returns the names of all map files in the first 'maps' directory found
+// (see MapPath), except 'info.txt' (map format description).
+// The names can be loaded with LoadWorldMap.
+func MapNames() []string {
+	for _, dir := range []string{"maps/", "../maps/", "../../maps/"} {
+		files, err := filepath.Glob(dir + "*.txt")
+		if err != nil || len(files) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(files))
+		for _, f := range files {
+			if strings.EqualFold(filepath.Base(f), "info.txt") {
+				continue
+			}
+			names = append(names, strings.TrimSuffix(filepath.Base(f), filepath.Ext(f)))
+		}
+		return names
 	}
+	return nil
+}
 
+// SplitMap separates the optional header from the grid lines of a map text.
+// The UTF-8 magic bytes, all '\r' and the trailing '|' are removed.
+// The grid lines are not validated (see NewWorldMap).
+func SplitMap(b []byte) (header string, lines []string) {
 	// remove utf8 magic bytes
 	b = bytes.ReplaceAll(b, []byte{0xef, 0xbb, 0xbf}, []byte{})
 
-	// return new world
-	return NewWorldMap(b, endtime)
+	// split header
+	s := strings.ReplaceAll(string(b), "\r", "") // remove '\r'
+	header, s = cutHeader(s)
+
+	// split lines
+	s = strings.ReplaceAll(s, "|", "") // remove '|'
+	return header, strings.Split(s, "\n")
 }
 
 //--------  Getter  --------------------------------------------------------------------------------------------------//
@@ -145,6 +250,11 @@ func (m *WorldMap) Stats() (iteration, endtime uint64, maxUpdateTime time.Durati
 	return m.iteration, m.endtime, m.maxUpdateTime
 }
 
+// UpdateTime returns the running time of the last Update() call (see Stats for the longest).
+func (m *WorldMap) UpdateTime() time.Duration {
+	return m.updateTime
+}
+
 // XWidth returns the grid width
 func (m *WorldMap) XWidth() int {
 	return m.xWidth
@@ -206,24 +316,17 @@ func (m *WorldMap) CellByVector(v *Vector) *Cell {
 	return m.Cell(xCol, yRow)
 }
 
-// TouchingCells returns all cells touched by a ship.
+// TouchingCells returns all cells touched by a ship of the normal class.
 // The ship is slightly smaller than a cell.
 func (m *WorldMap) TouchingCells(v *Vector) []*Cell {
-	r := CellRadius - CellRadius*0.1 - 1
-
-	// get all possible cells
-	all := make([]*Cell, 0, 8)
-	all = append(all, m.CellByVector(NewVector(v.X()+r, v.Y()-r)))
-	all = append(all, m.CellByVector(NewVector(v.X()-r, v.Y()+r)))
-	all = append(all, m.CellByVector(NewVector(v.X()+r, v.Y()+r)))
-	all = append(all, m.CellByVector(NewVector(v.X()-r, v.Y()-r)))
-	all = append(all, m.CellByVector(NewVector(v.X()+r, v.Y())))
-	all = append(all, m.CellByVector(NewVector(v.X()-r, v.Y())))
-	all = append(all, m.CellByVector(NewVector(v.X(), v.Y()+r)))
-	all = append(all, m.CellByVector(NewVector(v.X(), v.Y()-r)))
+	return m.touchingCells(v, CellRadius)
+}
 
-	// return (distinct)
-	return removeDuplicate(all)
+// Teleport returns the paired teleport cell or nil if there is no partner.
+// Teleport cells are paired in the order in which they appear in the map
+// (row by row, left to right): the first with the second, the third with the fourth, ...
+func (m *WorldMap) Teleport(c *Cell) *Cell {
+	return m.teleports[c]
 }
 
 // FreeSpawn returns a random, free spawn point.
@@ -233,9 +336,12 @@ func (m *WorldMap) FreeSpawn() *Vector {
 	free := make([]*Cell, 0, len(m.spawns)+1)
 
 	for _, spawn := range m.spawns {
+		if spawn.Type() == None || spawn.Type() == Blocked {
+			continue // removed (see ShrinkArena and MapEvent)
+		}
 		isFree := true
 		for _, player := range m.players {
-			for _, cell := range m.TouchingCells(player.Position()) {
+			for _, cell := range player.TouchingCells() {
 				if cell.Center().X() == spawn.Center().X() && cell.Center().Y() == spawn.Center().Y() {
 					isFree = false
 				}
@@ -251,6 +357,12 @@ func (m *WorldMap) FreeSpawn() *Vector {
 	rand.Shuffle(len(free), func(i, j int) { free[i], free[j] = free[j], free[i] })
 
 	// add fallback spawn
+	for _, spawn := range m.spawns {
+		if spawn.Type() != None && spawn.Type() != Blocked {
+			free = append(free, spawn) // occupied, but still there
+			break
+		}
+	}
 	free = append(free, m.spawns[0]) // error fallback
 
 	// return
@@ -264,12 +376,18 @@ func (m *WorldMap) Freeze(f bool) {
 	m.freeze = f
 }
 
+// OnUpdate registers a listener that is called after each world update (e.g. recording a replay).
+// The running time of the listeners is not part of the update time (see UpdateTime).
+func (m *WorldMap) OnUpdate(f func(m *WorldMap)) {
+	m.onUpdate = append(m.onUpdate, f)
+}
+
 // Update updates the world (move, score, velocity, ...).
 // Call this several times per second in the background. (default 60/s)
 func (m *WorldMap) Update() {
 
 	// Freeze
-	if m.freeze || m.iteration > m.endtime {
+	if m.freeze || m.gameOver {
 		return // no updates
 	}
 
@@ -278,9 +396,23 @@ func (m *WorldMap) Update() {
 	//--------------------------------------
 
 	// do your thing
+	m.updateMapEvents()
 	for _, ship := range m.players {
+		if ship.controller != nil && ship.IsAlive() {
+			ship.controller.Control(m, ship)
+		}
 		ship.Update()
 	}
+	if m.gameMode != nil {
+		m.gameMode.Update(m)
+	}
+	for _, ship := range m.players {
+		if ship.IsAlive() {
+			m.emit(Event{Type: EventTick, Ship: ship})
+		}
+	}
+	m.updateStars()
+	m.updateResult()
 
 	// build protocol
 	pOut := make([]byte, 0, 2000)
@@ -288,8 +420,9 @@ func (m *WorldMap) Update() {
 	if m.iteration%2 == 0 {
 		pOut = append(pOut, []byte(ProtocolPlayer(m))...)
 	}
-	if m.iteration%5 == 0 {
+	if m.iteration%5 == 0 || m.mapChanged {
 		pOut = append(pOut, []byte(ProtocolMap(m))...)
+		m.mapChanged = false
 	}
 
 	// send protocol to remote
@@ -317,6 +450,7 @@ func (m *WorldMap) Update() {
 	//--------------------------------------
 	// maxUpdateTime
 	duration := time.Since(start)
+	m.updateTime = duration
 	if m.maxUpdateTime.Microseconds() < duration.Microseconds() {
 		m.maxUpdateTime = duration
 		if m.maxUpdateTime > 16*time.Millisecond {
@@ -324,14 +458,21 @@ func (m *WorldMap) Update() {
 			fmt.Println("WARNING:", "maxUpdateTime", duration)
 		}
 	}
+
+	// listeners (see OnUpdate)
+	for _, f := range m.onUpdate {
+		f(m)
+	}
 }
 
 // AddPlayer registers and spawns a new player in the world.
 // A unique name must be set.
-// A valid color must be set (red, blue, green or orange).
+// A valid color must be set (red, blue, green, orange or a hex color like '#ff8800', see ParseColor).
+// The team is optional (empty string) and up to 20 characters long (see Ship.Team).
+// The class is optional (empty string is the normal class, see ShipClasses).
 // For remote param see Ship.Remote().
 // There is a maximum number of players (see MaxPlayers).
-func (m *WorldMap) AddPlayer(name, color string, remote io.ReadWriter) (playerID int, err error) {
+func (m *WorldMap) AddPlayer(name, color, team, class string, remote io.ReadWriter) (playerID int, err error) {
 	// check max player
 	if len(m.players) >= m.MaxPlayers() {
 		return -1, errors.New("maximum number of players reached")
@@ -353,29 +494,55 @@ func (m *WorldMap) AddPlayer(name, color string, remote io.ReadWriter) (playerID
 	}
 
 	// check color
-	if color != "red" && color != "blue" && color != "green" && color != "orange" {
-		return -1, errors.New("player color must be red, blue, green or orange")
+	color = strings.ToLower(strings.TrimSpace(color))
+	if _, err := ParseColor(color); err != nil {
+		return -1, err
+	}
+
+	// check team
+	team = strings.TrimSpace(team)
+	if len(team) > 20 || strings.ContainsAny(team, "|:=;,\r\n") {
+		return -1, errors.New("team name must be up to 20 characters long and must not contain '|:=;,'")
+	}
+
+	// check class
+	shipClass, err := ParseShipClass(class)
+	if err != nil {
+		return -1, err
 	}
 
 	// add / spawn
 	playerID = len(m.players)
-	ship := NewShip(m, playerID, name, color, remote)
+	ship := NewShip(m, playerID, name, color, team, shipClass, remote)
 	m.players = append(m.players, ship)
 
 	// set spawn position
 	ship.Spawn()
 
-	// start move command listener
+	// start command listener (move, dash, brake and draw)
 	if remote != nil {
 		go func(r io.ReadWriter, p *Ship) {
+			// prepare line reader
+			// (one reader for all lines, several commands can arrive at once)
+			tp := textproto.NewReader(bufio.NewReader(r))
 			for {
-				// prepare line reader
-				tp := textproto.NewReader(bufio.NewReader(r))
-				// read first line (ended with \n or \r\n)
-				line, _ := tp.ReadLine()
+				// read next line (ended with \n or \r\n)
+				line, err := tp.ReadLine()
+				if err != nil {
+					return // connection closed
+				}
 				// parse param
 				param := strings.Split(line, "|")
-				if len(param) == 2 {
+				if strings.EqualFold(strings.TrimSpace(param[0]), "DRAW") {
+					_ = p.Draw(param[1:]) // no server response (see protocol)
+				} else if len(param) == 1 {
+					switch strings.ToUpper(strings.TrimSpace(param[0])) {
+					case "DASH":
+						p.Dash()
+					case "BRAKE":
+						p.Brake()
+					}
+				} else if len(param) == 2 {
 					x, errX := strconv.ParseFloat(param[0], 64)
 					y, errY := strconv.ParseFloat(param[1], 64)
 					if errX == nil && errY == nil {
@@ -418,6 +585,48 @@ func (m *WorldMap) Print() {
 	fmt.Print("+\n")
 }
 
+// linkTeleports pairs all teleport cells (see Teleport).
+func (m *WorldMap) linkTeleports() {
+	m.teleports = make(map[*Cell]*Cell)
+
+	var open *Cell
+	for yRow := 0; yRow < m.yHeight; yRow++ {
+		for xCol := 0; xCol < m.xWidth; xCol++ {
+			c := m.grid[xCol][yRow]
+			if c.Type() != Teleport {
+				continue
+			}
+			if open == nil {
+				open = c
+			} else {
+				m.teleports[open] = c
+				m.teleports[c] = open
+				open = nil
+			}
+		}
+	}
+}
+
+// touchingCells returns all cells touched by a ship with the given radius.
+// The ship is slightly smaller than its radius.
+func (m *WorldMap) touchingCells(v *Vector, radius float64) []*Cell {
+	r := radius - radius*0.1 - 1
+
+	// get all possible cells
+	all := make([]*Cell, 0, 8)
+	all = append(all, m.CellByVector(NewVector(v.X()+r, v.Y()-r)))
+	all = append(all, m.CellByVector(NewVector(v.X()-r, v.Y()+r)))
+	all = append(all, m.CellByVector(NewVector(v.X()+r, v.Y()+r)))
+	all = append(all, m.CellByVector(NewVector(v.X()-r, v.Y()-r)))
+	all = append(all, m.CellByVector(NewVector(v.X()+r, v.Y())))
+	all = append(all, m.CellByVector(NewVector(v.X()-r, v.Y())))
+	all = append(all, m.CellByVector(NewVector(v.X(), v.Y()+r)))
+	all = append(all, m.CellByVector(NewVector(v.X(), v.Y()-r)))
+
+	// return (distinct)
+	return removeDuplicate(all)
+}
+
 // removeDuplicate removes cell duplicates from the list.
 func removeDuplicate(in []*Cell) []*Cell {
 	allKeys := make(map[string]bool)
@@ -1,9 +1,9 @@
 use nom::{
          IResult,
          branch::alt,
-         bytes::complete::{tag, take_until1},
+         bytes::complete::{tag, take_till, take_till1, take_until1},
          combinator::opt,
-         character::complete::{char, digit1, line_ending, u32},
+         character::complete::{char, digit1, line_ending, none_of, u32},
          error::{Error as NomError},
          multi::many_till,
          sequence::tuple,
@@ -52,6 +52,8 @@ pub enum Color {
     Blue,
     Green,
     Orange,
+    /// hex RGB color, e.g. `#ff8800`
+    Hex(String),
 }
 
 impl From<&str> for Color {
@@ -61,6 +63,7 @@ impl From<&str> for Color {
             "blue" => Color::Blue,
             "green" => Color::Green,
             "orange" => Color::Orange,
+            hex if hex.starts_with('#') => Color::Hex(hex.to_lowercase()),
             _ => Color::Red,
         }
     }
@@ -73,6 +76,7 @@ impl Display for Color {
             Color::Blue => "blue",
             Color::Green => "green",
             Color::Orange => "orange",
+            Color::Hex(hex) => hex.as_str(),
         };
         write!(fmt, "{color}")
     }
@@ -193,6 +197,8 @@ pub struct StatusBlock {
     pub end_time: usize,
     pub max_update_time: String,
     pub max_players: usize,
+    /// lines of newer servers after MaxPlayers as (key, value), e.g. ("GameOver", "false") or ("Team", "red=120")
+    pub extra: Vec<(String, String)>,
 }
 
 #[derive(Debug, PartialEq)]
@@ -207,6 +213,8 @@ pub struct Player {
     pub angle: f32,
     pub touching_cells: Vec<(usize, usize)>,
     pub alive: bool,
+    /// fields of newer servers after IsAlive as (key, value), e.g. ("Shield", "0") or ("Team", "red")
+    pub extra: Vec<(String, String)>,
 }
 
 #[derive(Debug, PartialEq)]
@@ -224,6 +232,19 @@ pub enum Cell {
     Star,
     AntiStar,
     Spawn,
+    Shield,
+    Mass,
+    Teleport,
+    Crumble,
+    ConveyorUp,
+    ConveyorDown,
+    ConveyorLeft,
+    ConveyorRight,
+    Hill,
+    Flag,
+    Goal,
+    /// cell of a newer server that this library does not know
+    Unknown(char),
 }
 
 
@@ -243,7 +264,18 @@ impl From<char> for Cell {
             'x' => Cell::Star,
             'a' => Cell::AntiStar,
             'o' => Cell::Spawn,
-            _ => panic!("unexcpected cell {cell}"),
+            'h' => Cell::Shield,
+            'm' => Cell::Mass,
+            't' => Cell::Teleport,
+            'c' => Cell::Crumble,
+            '^' => Cell::ConveyorUp,
+            'v' => Cell::ConveyorDown,
+            '<' => Cell::ConveyorLeft,
+            '>' => Cell::ConveyorRight,
+            'k' => Cell::Hill,
+            'f' => Cell::Flag,
+            'g' => Cell::Goal,
+            _ => Cell::Unknown(cell),
         }
     }
 }
@@ -254,16 +286,22 @@ fn parse_status_block(block: &str) -> IResult<&str, Event> {
     let (block, end_time) = number_after_tag(block, "Endtime:")?;
     let (block, max_update_time) = text_after_tag_postfix(block, "MaxUpdateTime:", "\n")?;
     let (block, max_players) = number_after_tag(block, "MaxPlayers:")?;
-    let (block, _end) = needle(block, "END STATUS\n")?;
+    let (block, (extra, _)) = many_till(parse_status_line, tag("END STATUS\n"))(block)?;
     
     Ok((block, Event::Status(StatusBlock {
         iteration,
         end_time,
         max_update_time: max_update_time.to_string(),
         max_players,
+        extra,
     })))
 }
 
+fn parse_status_line(block: &str) -> IResult<&str, (String, String)> {
+    let (block, (key, _, value, _)) = tuple((take_till1(|c: char| c == ':' || c == '\n'), char(':'), take_till(|c: char| c == '\n'), char('\n')))(block)?;
+    Ok((block, (key.to_string(), value.to_string())))
+}
+
 fn parse_player_block(block: &str) -> IResult<&str, Event> {
     let (block, _start) = needle(block, "START PLAYER\n")?;
     let (block, (players, _)) = many_till(parse_player, tag("END PLAYER\n"))(block)?;
@@ -290,7 +328,8 @@ fn parse_player(block: &str) -> IResult<&str, Player> {
     let (block, score) = number_after_tag_postfix(block, "Score:", "|")?;
     let (block, angle) = float_after_tag_postfix(block, "Angle:", "|")?;
     let (block, cells) = touching_cells(block)?;
-    let (block, alive) = bool_after_tag_postfix(block, "IsAlive:", "\n")?;
+    let (block, (_, alive)) = tuple((tag("IsAlive:"), take_till1(|c: char| c == '|' || c == '\n')))(block)?;
+    let (block, (extra, _)) = many_till(parse_extra_field, line_ending)(block)?;
 
     Ok((block, Player {
         id,
@@ -302,12 +341,18 @@ fn parse_player(block: &str) -> IResult<&str, Player> {
         score,
         angle,
         touching_cells: cells,
-        alive: alive.0
+        alive: BumperBool::from(alive).0,
+        extra,
     }))
 }
 
+fn parse_extra_field(block: &str) -> IResult<&str, (String, String)> {
+    let (block, (_, key, _, value)) = tuple((char('|'), take_till1(|c: char| c == ':' || c == '|' || c == '\n'), char(':'), take_till(|c: char| c == '|' || c == '\n')))(block)?;
+    Ok((block, (key.to_string(), value.to_string())))
+}
+
 fn parse_map_row(block: &str) -> IResult<&str, Vec<Cell>> {
-    let (block, (row_cells, _)) = many_till(alt((char('.'), char(' '), char('#'), char('s'), char('b'), char('x'), char('a'), char('o'))), line_ending)(block)?;
+    let (block, (row_cells, _)) = many_till(none_of("\r\n"), line_ending)(block)?;
     Ok((block, row_cells.into_iter().map(Cell::from).collect()))
 }
 
@@ -346,10 +391,6 @@ impl From<&str> for BumperBool {
     }
 }
 
-fn bool_after_tag_postfix<'a>(haystack: &'a str, prefix: &str, postfix: &str) -> IResult<&'a str, BumperBool> {
-    any_after_tag_postfix(haystack, prefix, postfix)
-}
-
 fn color_after_tag_postfix<'a>(haystack: &'a str, prefix: &str, postfix: &str) -> IResult<&'a str, Color> {
     any_after_tag_postfix(haystack, prefix, postfix)
 }
@@ -420,10 +461,38 @@ END STATUS
             iteration: 0,
             end_time: 33572,
             max_update_time: "0s".to_string(),
-            max_players: 4
+            max_players: 4,
+            extra: vec![],
         }))))
     }
 
+    #[test]
+    fn should_parse_status_block_with_extra_lines() {
+        let block = "START STATUS
+Iteration:10800
+Endtime:10800
+MaxUpdateTime:1.2ms
+MaxPlayers:4
+Team:red=120
+Overtime:false
+GameOver:true
+Winner:2
+END STATUS
+";
+        let (_, event) = parse_status_block(block).expect("valid block");
+        if let Event::Status(status) = event {
+            assert_eq!(status.max_players, 4);
+            assert_eq!(status.extra, vec![
+                ("Team".to_string(), "red=120".to_string()),
+                ("Overtime".to_string(), "false".to_string()),
+                ("GameOver".to_string(), "true".to_string()),
+                ("Winner".to_string(), "2".to_string()),
+            ]);
+        } else {
+            panic!("expected status block");
+        }
+    }
+
     #[test]
     fn should_parse_player_block() {
         let block = "START PLAYER
@@ -445,6 +514,7 @@ END PLAYER
                     angle: 0.,
                     touching_cells: vec![(20, 4)],
                     alive: true,
+                    extra: vec![],
                 },
                 Player {
                     id: 1,
@@ -457,11 +527,57 @@ END PLAYER
                     angle: 0.,
                     touching_cells: vec![(18, 14),(5, 3)],
                     alive: true,
+                    extra: vec![],
                 },
             ]
         }))))
     }
 
+    #[test]
+    fn should_parse_player_with_extra_fields() {
+        let block = "START PLAYER
+PlayerID:0|Name:Bot|Color:#e0c000|Position:820.000000,180.000000|Velocity:0.000000,0.000000|Acceleration:0.000000,0.000000|Score:100|Angle:0.000000|TouchingCells:20,4;|IsAlive:true|Shield:0|Team:|Class:normal
+END PLAYER
+";
+        let (_, event) = parse_player_block(block).expect("valid block");
+        if let Event::Player(block) = event {
+            assert!(block.players[0].alive);
+            assert_eq!(block.players[0].color, Color::Hex("#e0c000".to_string()));
+            assert_eq!(block.players[0].extra, vec![
+                ("Shield".to_string(), "0".to_string()),
+                ("Team".to_string(), "".to_string()),
+                ("Class".to_string(), "normal".to_string()),
+            ]);
+        } else {
+            panic!("expected player block");
+        }
+    }
+
+    #[test]
+    fn should_round_trip_hex_colors() {
+        let color = Color::from("#E0C000");
+        assert_eq!(color, Color::Hex("#e0c000".to_string()));
+        assert_eq!(color.to_string(), "#e0c000");
+        assert_eq!(Color::from("orange").to_string(), "orange");
+    }
+
+    #[test]
+    fn should_parse_unknown_cells() {
+        let map = "START MAP
+c^t
+?..
+END MAP
+";
+        assert_eq!(parse_map_block(map), Ok(("",
+            Event::Map(MapBlock {
+                rows: vec![
+                    vec![Cell::Crumble, Cell::ConveyorUp, Cell::Teleport],
+                    vec![Cell::Unknown('?'), Cell::Ground, Cell::Ground],
+                ],
+            })))
+        );
+    }
+
     #[test]
     fn should_parse_map_block() {
         let map = "START MAP
@@ -95,7 +95,7 @@ def parse_ply(text):
     for line in text.split("\n"):
         # split elements
         els = line.split("|")
-        if len(els) == 10:
+        if len(els) >= 10:
             for el in els:
                 # split args
                 args = el.split(":")
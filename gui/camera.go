@@ -0,0 +1,255 @@
+package gui
+
+import (
+	"SpaceBumper/core"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"math"
+)
+
+// CameraMode is the way the camera moves (see Camera).
+type CameraMode int
+
+const (
+	CameraFit    CameraMode = iota // the whole map fits the screen (default)
+	CameraFree                     // zoom and pan with the mouse
+	CameraFollow                   // the camera follows a ship
+)
+
+// String returns the name of the mode.
+func (c CameraMode) String() string {
+	switch c {
+	case CameraFree:
+		return "free"
+	case CameraFollow:
+		return "follow"
+	default:
+		return "fit"
+	}
+}
+
+const (
+	minZoom = 0.1 // smallest zoom factor
+	maxZoom = 4.0 // biggest zoom factor
+)
+
+// Camera transforms the world coordinates (pixels, see core.CellSize) to screen coordinates.
+// The camera looks at the point x,y of the world with the zoom factor.
+type Camera struct {
+	mode         CameraMode
+	zoom         float64 // screen pixels per world pixel
+	x, y         float64 // look-at point (world)
+	follow       int     // player id (see CameraFollow)
+	screenWidth  float64 // viewport
+	screenHeight float64 // viewport
+	worldWidth   float64
+	worldHeight  float64
+	dragX, dragY int  // last cursor position while panning
+	dragging     bool // pan with the mouse
+}
+
+// NewCamera returns a camera that fits the world into the screen.
+func NewCamera(worldWidth, worldHeight, screenWidth, screenHeight int) *Camera {
+	c := &Camera{
+		worldWidth:   float64(worldWidth),
+		worldHeight:  float64(worldHeight),
+		screenWidth:  float64(screenWidth),
+		screenHeight: float64(screenHeight),
+	}
+	c.Fit()
+	return c
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// Mode returns the camera mode.
+func (c *Camera) Mode() CameraMode {
+	return c.mode
+}
+
+// Zoom returns the zoom factor (1.0 = 1 world pixel per screen pixel).
+func (c *Camera) Zoom() float64 {
+	return c.zoom
+}
+
+// Following returns the id of the followed player (see CameraFollow).
+func (c *Camera) Following() int {
+	return c.follow
+}
+
+// GeoM returns the transformation from world to screen coordinates.
+func (c *Camera) GeoM() ebiten.GeoM {
+	var m ebiten.GeoM
+	m.Translate(-c.x, -c.y)
+	m.Scale(c.zoom, c.zoom)
+	m.Translate(c.screenWidth/2, c.screenHeight/2)
+	return m
+}
+
+// WorldToScreen converts world coordinates to screen coordinates.
+func (c *Camera) WorldToScreen(x, y float64) (float64, float64) {
+	return (x-c.x)*c.zoom + c.screenWidth/2, (y-c.y)*c.zoom + c.screenHeight/2
+}
+
+// ScreenToWorld converts screen coordinates (e.g. the cursor position) to world coordinates.
+func (c *Camera) ScreenToWorld(x, y float64) (float64, float64) {
+	return (x-c.screenWidth/2)/c.zoom + c.x, (y-c.screenHeight/2)/c.zoom + c.y
+}
+
+// Visible returns the visible cell range (inclusive) clipped to the world.
+func (c *Camera) Visible() (x0, y0, x1, y1 int) {
+	wx0, wy0 := c.ScreenToWorld(0, 0)
+	wx1, wy1 := c.ScreenToWorld(c.screenWidth, c.screenHeight)
+	x0 = int(math.Max(0, math.Floor(wx0/core.CellSize)))
+	y0 = int(math.Max(0, math.Floor(wy0/core.CellSize)))
+	x1 = int(math.Min(c.worldWidth/core.CellSize-1, math.Floor(wx1/core.CellSize)))
+	y1 = int(math.Min(c.worldHeight/core.CellSize-1, math.Floor(wy1/core.CellSize)))
+	return
+}
+
+//--------  Setter  --------------------------------------------------------------------------------------------------//
+
+// SetViewport sets the screen size (e.g. after a window resize).
+func (c *Camera) SetViewport(screenWidth, screenHeight int) {
+	c.screenWidth = float64(screenWidth)
+	c.screenHeight = float64(screenHeight)
+	if c.mode == CameraFit {
+		c.Fit()
+	}
+}
+
+// Fit shows the whole world (see CameraFit).
+func (c *Camera) Fit() {
+	c.mode = CameraFit
+	c.zoom = c.fitZoom()
+	c.x, c.y = c.worldWidth/2, c.worldHeight/2
+}
+
+// Follow follows the player with the id (see CameraFollow).
+// The zoom is set to 1:1 if the whole world is visible.
+func (c *Camera) Follow(id int) {
+	if c.mode == CameraFit && c.zoom < 1 {
+		c.zoom = 1
+	}
+	c.mode = CameraFollow
+	c.follow = id
+}
+
+// Pan moves the camera by screen pixels (see CameraFree).
+func (c *Camera) Pan(dx, dy float64) {
+	c.mode = CameraFree
+	c.x -= dx / c.zoom
+	c.y -= dy / c.zoom
+	c.clamp()
+}
+
+// ZoomAt zooms by the factor and keeps the screen point x,y at the same world position.
+// The followed ship stays in the center (see CameraFollow).
+func (c *Camera) ZoomAt(factor, x, y float64) {
+	if c.mode == CameraFit {
+		c.mode = CameraFree
+	}
+	wx, wy := c.ScreenToWorld(x, y)
+	c.zoom = math.Max(minZoom, math.Min(maxZoom, c.zoom*factor))
+	if c.mode == CameraFree {
+		nx, ny := c.ScreenToWorld(x, y)
+		c.x += wx - nx
+		c.y += wy - ny
+	}
+	c.clamp()
+}
+
+//--------  Update  --------------------------------------------------------------------------------------------------//
+
+// Update handles the mouse and key input and moves the camera to the followed ship.
+// The ship position is interpolated (see Game.position).
+//
+//	mouse wheel, +/-   zoom
+//	right mouse        pan
+//	F                  fit the whole map
+//	C                  follow the next ship
+func (c *Camera) Update(g *Game) {
+
+	// zoom
+	cx, cy := ebiten.CursorPosition()
+	if _, wy := ebiten.Wheel(); wy != 0 {
+		c.ZoomAt(math.Pow(1.1, wy), float64(cx), float64(cy))
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyEqual) || ebiten.IsKeyPressed(ebiten.KeyNumpadAdd) {
+		c.ZoomAt(1.02, c.screenWidth/2, c.screenHeight/2)
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyMinus) || ebiten.IsKeyPressed(ebiten.KeyNumpadSubtract) {
+		c.ZoomAt(1/1.02, c.screenWidth/2, c.screenHeight/2)
+	}
+
+	// pan
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) {
+		if c.dragging && (cx != c.dragX || cy != c.dragY) {
+			c.Pan(float64(cx-c.dragX), float64(cy-c.dragY))
+		}
+		c.dragX, c.dragY, c.dragging = cx, cy, true
+	} else {
+		c.dragging = false
+	}
+
+	// modes
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		c.Fit()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		c.followNext(g.world)
+	}
+
+	// follow
+	if c.mode == CameraFollow {
+		ship, err := g.world.Player(c.follow)
+		if err != nil {
+			c.Fit()
+			return
+		}
+		pos := g.position(ship)
+		c.x, c.y = pos.X(), pos.Y()
+		c.clamp()
+	}
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+// followNext follows the next living ship after the followed one.
+func (c *Camera) followNext(world *core.WorldMap) {
+	players := world.Players()
+	start := c.follow + 1
+	if c.mode != CameraFollow {
+		start = 0
+	}
+	for i := 0; i < len(players); i++ {
+		id := (start + i) % len(players)
+		if players[id].IsAlive() {
+			c.Follow(id)
+			return
+		}
+	}
+}
+
+// fitZoom returns the zoom factor that shows the whole world.
+func (c *Camera) fitZoom() float64 {
+	if c.worldWidth <= 0 || c.worldHeight <= 0 {
+		return 1
+	}
+	return math.Min(c.screenWidth/c.worldWidth, c.screenHeight/c.worldHeight)
+}
+
+// clamp keeps the world on the screen.
+// An axis that fits the screen is centered.
+func (c *Camera) clamp() {
+	c.x = clampAxis(c.x, c.worldWidth, c.screenWidth/c.zoom)
+	c.y = clampAxis(c.y, c.worldHeight, c.screenHeight/c.zoom)
+}
+
+// clampAxis returns the look-at point of one axis.
+func clampAxis(v, world, visible float64) float64 {
+	if visible >= world {
+		return world / 2
+	}
+	return math.Max(visible/2, math.Min(world-visible/2, v))
+}
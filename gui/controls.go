@@ -0,0 +1,183 @@
+package gui
+
+import (
+	"SpaceBumper/core"
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// deadZone ignores small gamepad stick values (stick drift)
+const deadZone = 0.2
+
+// KeySet are the keys of a keyboard control (see Bindings).
+type KeySet struct {
+	Up, Down, Left, Right ebiten.Key
+	Dash, Brake           ebiten.Key
+}
+
+// key sets of the control presets (see ParseBindings)
+var (
+	KeysWASD   = KeySet{Up: ebiten.KeyW, Down: ebiten.KeyS, Left: ebiten.KeyA, Right: ebiten.KeyD, Dash: ebiten.KeySpace, Brake: ebiten.KeyShiftLeft}
+	KeysArrows = KeySet{Up: ebiten.KeyArrowUp, Down: ebiten.KeyArrowDown, Left: ebiten.KeyArrowLeft, Right: ebiten.KeyArrowRight, Dash: ebiten.KeyEnter, Brake: ebiten.KeyShiftRight}
+)
+
+// Bindings maps the input devices to the commands of a local ship (move, dash and brake).
+// All bound devices can be used at the same time (see ParseBindings).
+//
+//	mouse:    the ship accelerates towards the cursor while the left mouse button is pressed
+//	keys:     eight directions with full acceleration, dash and brake
+//	gamepads: the left stick accelerates (analog), A (button 0) dashes and B (button 1) brakes
+type Bindings struct {
+	Mouse    bool
+	Keys     []KeySet
+	Gamepads []int // index of the connected gamepads (0 = first)
+}
+
+// DefaultBindings returns the bindings of a single local player: mouse, WASD, arrows and the first gamepad.
+func DefaultBindings() Bindings {
+	return Bindings{
+		Mouse:    true,
+		Keys:     []KeySet{KeysWASD, KeysArrows},
+		Gamepads: []int{0},
+	}
+}
+
+// ParseBindings parses a comma separated list of input devices.
+// Custom keys are given as 'keys:up/down/left/right/dash/brake' with ebiten key names.
+//
+//	all                  mouse, wasd, arrows and gamepad (see DefaultBindings)
+//	mouse                left mouse button
+//	wasd                 W, A, S, D, Space (dash), ShiftLeft (brake)
+//	arrows               arrow keys, Enter (dash), ShiftRight (brake)
+//	gamepad, gamepad2    first (second, ...) gamepad
+//	keys:I/K/J/L/U/O     custom keys
+func ParseBindings(s string) (Bindings, error) {
+	var b Bindings
+	for _, dev := range strings.Split(s, ",") {
+		dev = strings.ToLower(strings.TrimSpace(dev))
+		switch {
+		case dev == "all":
+			d := DefaultBindings()
+			b.Mouse = true
+			b.Keys = append(b.Keys, d.Keys...)
+			b.Gamepads = append(b.Gamepads, d.Gamepads...)
+		case dev == "mouse":
+			b.Mouse = true
+		case dev == "wasd":
+			b.Keys = append(b.Keys, KeysWASD)
+		case dev == "arrows":
+			b.Keys = append(b.Keys, KeysArrows)
+		case strings.HasPrefix(dev, "gamepad"):
+			n := 1
+			if nr := strings.TrimPrefix(dev, "gamepad"); nr != "" {
+				var err error
+				if n, err = strconv.Atoi(nr); err != nil || n < 1 {
+					return b, fmt.Errorf("invalid gamepad '%s': use gamepad, gamepad2, ...", dev)
+				}
+			}
+			b.Gamepads = append(b.Gamepads, n-1)
+		case strings.HasPrefix(dev, "keys:"):
+			names := strings.Split(strings.TrimPrefix(dev, "keys:"), "/")
+			if len(names) != 6 {
+				return b, fmt.Errorf("invalid keys '%s': use keys:up/down/left/right/dash/brake", dev)
+			}
+			keys := make([]ebiten.Key, len(names))
+			for i, name := range names {
+				if err := keys[i].UnmarshalText([]byte(name)); err != nil {
+					return b, fmt.Errorf("invalid key '%s'", name)
+				}
+			}
+			b.Keys = append(b.Keys, KeySet{Up: keys[0], Down: keys[1], Left: keys[2], Right: keys[3], Dash: keys[4], Brake: keys[5]})
+		default:
+			return b, fmt.Errorf("invalid control '%s': use all, mouse, wasd, arrows, gamepad or keys:up/down/left/right/dash/brake", dev)
+		}
+	}
+	return b, nil
+}
+
+//--------  Update  --------------------------------------------------------------------------------------------------//
+
+// Control reads the bound devices and sends the commands to the ship.
+// The camera converts the cursor position to world coordinates.
+func (b Bindings) Control(ship *core.Ship, camera *Camera) {
+	var ax, ay float64
+	dash, brake := false, false
+
+	// mouse
+	if b.Mouse && ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		x, y := camera.ScreenToWorld(toFloat(ebiten.CursorPosition()))
+		pos := ship.Position()
+		ax += (x - pos.X()) / 100
+		ay += (y - pos.Y()) / 100
+	}
+
+	// keyboard
+	for _, k := range b.Keys {
+		var kx, ky float64
+		if ebiten.IsKeyPressed(k.Left) {
+			kx--
+		}
+		if ebiten.IsKeyPressed(k.Right) {
+			kx++
+		}
+		if ebiten.IsKeyPressed(k.Up) {
+			ky--
+		}
+		if ebiten.IsKeyPressed(k.Down) {
+			ky++
+		}
+		if l := math.Hypot(kx, ky); l > 0 {
+			ax += kx / l
+			ay += ky / l
+		}
+		dash = dash || ebiten.IsKeyPressed(k.Dash)
+		brake = brake || ebiten.IsKeyPressed(k.Brake)
+	}
+
+	// gamepads
+	ids := ebiten.AppendGamepadIDs(nil)
+	for _, i := range b.Gamepads {
+		if i >= len(ids) {
+			continue // not connected
+		}
+		gx, gy, d, br := gamepad(ids[i])
+		ax += gx
+		ay += gy
+		dash = dash || d
+		brake = brake || br
+	}
+
+	// commands (the strength is limited to 1, see core.Ship.Move)
+	ship.Move(core.NewVector(ax, ay))
+	if dash {
+		ship.Dash()
+	}
+	if brake {
+		ship.Brake()
+	}
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+// gamepad returns the left stick (dead zone applied) and the dash and brake buttons.
+// Gamepads without the standard layout use the first two axes and buttons.
+func gamepad(id ebiten.GamepadID) (x, y float64, dash, brake bool) {
+	if ebiten.IsStandardGamepadLayoutAvailable(id) {
+		x = ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+		y = ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+		dash = ebiten.IsStandardGamepadButtonPressed(id, ebiten.StandardGamepadButtonRightBottom)
+		brake = ebiten.IsStandardGamepadButtonPressed(id, ebiten.StandardGamepadButtonRightRight)
+	} else {
+		x = ebiten.GamepadAxisValue(id, 0)
+		y = ebiten.GamepadAxisValue(id, 1)
+		dash = ebiten.IsGamepadButtonPressed(id, ebiten.GamepadButton0)
+		brake = ebiten.IsGamepadButtonPressed(id, ebiten.GamepadButton1)
+	}
+	if math.Hypot(x, y) < deadZone {
+		x, y = 0, 0
+	}
+	return
+}
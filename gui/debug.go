@@ -0,0 +1,245 @@
+package gui
+
+import (
+	"SpaceBumper/core"
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"image/color"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	debugSamples = 180 // update time samples in the graph (one per tick)
+	debugEvents  = 10  // max. number of logged events
+	debugGraphH  = 40  // graph height (pixels)
+)
+
+var (
+	debugCircle   = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	debugCollide  = color.RGBA{R: 0xff, G: 0x40, B: 0x40, A: 0xff}
+	debugVelocity = color.RGBA{R: 0x40, G: 0xff, B: 0x40, A: 0xff}
+	debugAccel    = color.RGBA{R: 0xff, G: 0xe0, B: 0x40, A: 0xff}
+	debugLink     = color.RGBA{R: 0xff, G: 0x40, B: 0xff, A: 0xff}
+	debugTouch    = color.RGBA{R: 0x00, G: 0x60, B: 0x60, A: 0x60}
+	debugWall     = color.RGBA{R: 0x80, G: 0x00, B: 0x00, A: 0x80}
+	debugBudget   = color.RGBA{R: 0xff, G: 0x40, B: 0x40, A: 0xff}
+)
+
+// Debug is the debug overlay of the core simulation (toggle with F3):
+//
+//	white circle   collision radius (red while colliding with another ship)
+//	green line     velocity (x5)
+//	yellow line    acceleration (x40)
+//	magenta line   last collider (gets the knock-out points)
+//	cyan cells     touching cells (red: blocked)
+//
+// The panel shows the update time of each tick, the ship states and the last scored events.
+// The overlay uses the positions of the last tick (not interpolated, see core.Clock).
+type Debug struct {
+	world   *core.WorldMap
+	enabled bool
+	samples []time.Duration // update time per tick (ring buffer)
+	next    int             // next sample index
+	events  []string        // newest last
+}
+
+// NewDebug creates the overlay (disabled) and registers the event and update listeners at the world.
+func NewDebug(world *core.WorldMap) *Debug {
+	d := &Debug{world: world, samples: make([]time.Duration, debugSamples)}
+	world.OnEvent(d.onEvent)
+	world.OnUpdate(d.onUpdate)
+	return d
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// Enabled returns true if the overlay is shown.
+func (d *Debug) Enabled() bool {
+	return d.enabled
+}
+
+//--------  Update  --------------------------------------------------------------------------------------------------//
+
+// Toggle shows or hides the overlay.
+func (d *Debug) Toggle() {
+	d.enabled = !d.enabled
+}
+
+// onEvent logs all events except the ticks (with the score deltas).
+func (d *Debug) onEvent(e core.Event) {
+	if e.Type == core.EventTick {
+		return
+	}
+
+	msg := fmt.Sprintf("%d %s %s", e.Iteration, e.Type, e.Ship.Name())
+	if e.Other != nil {
+		msg += fmt.Sprintf(" -> %s", e.Other.Name())
+	}
+	if e.Type == core.EventPickup {
+		msg += fmt.Sprintf(" '%c'", e.CellType)
+	}
+	if e.Speed > 0 {
+		msg += fmt.Sprintf(" speed=%.2f", e.Speed)
+	}
+	msg += fmt.Sprintf(" %+d", e.Delta)
+	if e.Other != nil {
+		msg += fmt.Sprintf("/%+d", e.OtherDelta)
+	}
+
+	d.events = append(d.events, msg)
+	if len(d.events) > debugEvents {
+		d.events = d.events[len(d.events)-debugEvents:]
+	}
+}
+
+// onUpdate records the update time of each tick (a frame can run several ticks, see core.Clock).
+func (d *Debug) onUpdate(m *core.WorldMap) {
+	d.samples[d.next] = m.UpdateTime()
+	d.next = (d.next + 1) % len(d.samples)
+}
+
+//--------  Draw  ----------------------------------------------------------------------------------------------------//
+
+// Draw draws the overlay (if enabled).
+func (d *Debug) Draw(screen *ebiten.Image, camera *Camera) {
+	if !d.enabled {
+		return
+	}
+	players := d.world.Players()
+
+	// touching cells
+	for _, s := range players {
+		if !s.IsAlive() {
+			continue
+		}
+		for _, c := range s.TouchingCells() {
+			clr := debugTouch
+			if c.Type() == core.Blocked {
+				clr = debugWall
+			}
+			x0, y0 := camera.WorldToScreen(float64(c.XCol()*core.CellSize), float64(c.YRow()*core.CellSize))
+			x1, y1 := camera.WorldToScreen(float64((c.XCol()+1)*core.CellSize), float64((c.YRow()+1)*core.CellSize))
+			ebitenutil.DrawRect(screen, x0, y0, x1-x0, y1-y0, clr)
+		}
+	}
+
+	// ships
+	for _, s := range players {
+		if !s.IsAlive() {
+			continue
+		}
+		pos := s.Position()
+		x, y := camera.WorldToScreen(pos.X(), pos.Y())
+
+		// collision circle
+		clr := debugCircle
+		for _, o := range players {
+			if o != s && o.IsAlive() && s.Collide(o) {
+				clr = debugCollide
+			}
+		}
+		drawCircle(screen, x, y, s.Radius()*camera.Zoom(), clr)
+
+		// vectors
+		v := s.Velocity()
+		drawVector(screen, camera, pos, v.X()*5, v.Y()*5, debugVelocity)
+		a := s.Acceleration()
+		drawVector(screen, camera, pos, a.X()*core.CellSize, a.Y()*core.CellSize, debugAccel)
+
+		// last collider
+		if o := s.LastCollider(); o != nil && o.IsAlive() {
+			op := o.Position()
+			ox, oy := camera.WorldToScreen(op.X(), op.Y())
+			ebitenutil.DrawLine(screen, x, y, ox, oy, debugLink)
+		}
+	}
+
+	d.drawPanel(screen)
+}
+
+// drawPanel draws the timing, the ship states and the event log (bottom left).
+func (d *Debug) drawPanel(screen *ebiten.Image) {
+	_, sh := screen.Size()
+	iteration, endtime, maxUpdateTime := d.world.Stats()
+
+	// text
+	sb := new(strings.Builder)
+	sb.WriteString(fmt.Sprintf("DEBUG (F3)  iteration=%d/%d  FPS=%.0f TPS=%.0f\n", iteration, endtime, ebiten.ActualFPS(), ebiten.ActualTPS()))
+	sb.WriteString(fmt.Sprintf("update=%v  avg=%v  max=%v\n", d.world.UpdateTime(), d.average(), maxUpdateTime))
+	for id, s := range d.world.Players() {
+		pos, v, a := s.Position(), s.Velocity(), s.Acceleration()
+		collider := "-"
+		if o := s.LastCollider(); o != nil {
+			collider = o.Name()
+		}
+		sb.WriteString(fmt.Sprintf("%d %-12.12s pos=(%6.1f,%6.1f) v=%5.2f a=%4.2f mass=%.2f shield=%d dash=%d brake=%d last=%s\n",
+			id, s.Name(), pos.X(), pos.Y(), v.Length(), a.Length(), s.Mass(), s.Shield(), s.DashCooldown(), s.BrakeCooldown(), collider))
+	}
+	sb.WriteString("events:\n")
+	for _, e := range d.events {
+		sb.WriteString("  " + e + "\n")
+	}
+	msg := sb.String()
+
+	// panel
+	lines := strings.Count(msg, "\n")
+	height := lines*16 + debugGraphH + 16
+	top := sh - height - hudPadding
+	ebitenutil.DrawRect(screen, hudPadding, float64(top), debugSamples*2+hudPadding*2, debugGraphH+hudPadding, hudPanel)
+	ebitenutil.DrawRect(screen, hudPadding, float64(top+debugGraphH+hudPadding), 720, float64(lines*16+hudPadding), hudPanel)
+
+	// update time graph (the red line is the budget of a tick at 60 TPS)
+	budget := time.Second / core.TPS
+	scale := float64(debugGraphH) / float64(budget)
+	base := float64(top + debugGraphH)
+	for i := 0; i < len(d.samples); i++ {
+		sample := d.samples[(d.next+i)%len(d.samples)]
+		h := math.Min(debugGraphH, float64(sample)*scale)
+		clr := debugVelocity
+		if sample > budget/2 {
+			clr = debugAccel
+		}
+		ebitenutil.DrawRect(screen, float64(hudPadding*2+i*2), base-h, 2, h, clr)
+	}
+	ebitenutil.DrawLine(screen, hudPadding*2, base-debugGraphH, hudPadding*2+debugSamples*2, base-debugGraphH, debugBudget)
+
+	ebitenutil.DebugPrintAt(screen, msg, hudPadding*2, top+debugGraphH+hudPadding)
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+// average returns the average update time of the recorded ticks.
+func (d *Debug) average() time.Duration {
+	var sum time.Duration
+	n := 0
+	for _, s := range d.samples {
+		if s > 0 {
+			sum += s
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / time.Duration(n)
+}
+
+// drawCircle draws a circle outline (screen coordinates).
+func drawCircle(screen *ebiten.Image, x, y, r float64, clr color.Color) {
+	const segments = 24
+	for i := 0; i < segments; i++ {
+		a0 := 2 * math.Pi * float64(i) / segments
+		a1 := 2 * math.Pi * float64(i+1) / segments
+		ebitenutil.DrawLine(screen, x+r*math.Cos(a0), y+r*math.Sin(a0), x+r*math.Cos(a1), y+r*math.Sin(a1), clr)
+	}
+}
+
+// drawVector draws a vector (world length) from the world position.
+func drawVector(screen *ebiten.Image, camera *Camera, from *core.Vector, dx, dy float64, clr color.Color) {
+	x0, y0 := camera.WorldToScreen(from.X(), from.Y())
+	x1, y1 := camera.WorldToScreen(from.X()+dx, from.Y()+dy)
+	ebitenutil.DrawLine(screen, x0, y0, x1, y1, clr)
+}
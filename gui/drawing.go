@@ -0,0 +1,92 @@
+package gui
+
+import (
+	"SpaceBumper/core"
+	"SpaceBumper/gui/resources"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// digitKeys toggle the drawings of the players 1 to 9, with Ctrl 10 to 18 (see Drawings)
+var digitKeys = []ebiten.Key{ebiten.Key1, ebiten.Key2, ebiten.Key3, ebiten.Key4, ebiten.Key5, ebiten.Key6, ebiten.Key7, ebiten.Key8, ebiten.Key9}
+
+// Drawings draws the debug shapes of the bots in the player color (see core.Ship.Drawing and the DRAW command).
+// The drawings are shown by default and can be toggled per player:
+//
+//	1 - 9        toggle the drawing of the player id 0 - 8
+//	Ctrl 1 - 9   toggle the drawing of the player id 9 - 17 (Shift is the brake of the local players)
+//	0            hide all drawings (or show all if all are hidden)
+type Drawings struct {
+	world  *core.WorldMap
+	hidden map[int]bool // player id -> hidden
+}
+
+// NewDrawings creates the bot drawings of the world (all shown).
+func NewDrawings(world *core.WorldMap) *Drawings {
+	return &Drawings{world: world, hidden: make(map[int]bool)}
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// Hidden returns true if the drawing of the player is hidden.
+func (d *Drawings) Hidden(id int) bool {
+	return d.hidden[id]
+}
+
+//--------  Update  --------------------------------------------------------------------------------------------------//
+
+// Toggle shows or hides the drawing of the player.
+func (d *Drawings) Toggle(id int) {
+	d.hidden[id] = !d.hidden[id]
+}
+
+// Update handles the toggle keys.
+func (d *Drawings) Update() {
+	offset := 0
+	if ebiten.IsKeyPressed(ebiten.KeyControl) {
+		offset = len(digitKeys)
+	}
+	for i, k := range digitKeys {
+		if inpututil.IsKeyJustPressed(k) {
+			d.Toggle(i + offset)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.Key0) {
+		players := d.world.Players()
+		all := true
+		for id := range players {
+			all = all && d.hidden[id]
+		}
+		for id := range players {
+			d.hidden[id] = !all
+		}
+	}
+}
+
+//--------  Draw  ----------------------------------------------------------------------------------------------------//
+
+// Draw draws the shown drawings of all players.
+// The positions and circle radii follow the camera, the line width and the label size are not scaled by the zoom.
+func (d *Drawings) Draw(screen *ebiten.Image, camera *Camera) {
+	face := resources.Fonts.ToolTipFace
+	for id, s := range d.world.Players() {
+		if d.hidden[id] {
+			continue
+		}
+		clr := s.RGB()
+		for _, sh := range s.Drawing() {
+			x, y := camera.WorldToScreen(sh.X, sh.Y)
+			switch sh.Kind {
+			case core.ShapeLine:
+				x2, y2 := camera.WorldToScreen(sh.X2, sh.Y2)
+				ebitenutil.DrawLine(screen, x, y, x2, y2, clr)
+			case core.ShapeCircle:
+				drawCircle(screen, x, y, sh.Radius*camera.Zoom(), clr)
+			case core.ShapeLabel:
+				text.Draw(screen, sh.Text, face, int(x), int(y), clr)
+			}
+		}
+	}
+}
@@ -0,0 +1,553 @@
+package gui
+
+import (
+	"SpaceBumper/core"
+	"SpaceBumper/gui/resources"
+	"SpaceBumper/maplint"
+	"fmt"
+	"github.com/blizzy78/ebitenui"
+	"github.com/blizzy78/ebitenui/widget"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"golang.org/x/image/font"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// editor layout
+const (
+	editorSidebar   = 360 // min. sidebar width (px)
+	editorMinHeight = 720 // min. window height (px)
+	editorMaxSize   = 200 // max. grid width and height (cells)
+	editorLintLines = 12  // max. lint issues in the sidebar
+)
+
+// cellNames are the names of the cell types shown in the editor.
+var cellNames = map[byte]string{
+	core.None:          "void",
+	core.Blocked:       "block",
+	core.Boost:         "boost",
+	core.Slow:          "slow",
+	core.Tile:          "tile",
+	core.Star:          "star",
+	core.Anti:          "anti-star",
+	core.Spawn:         "spawn",
+	core.Shield:        "shield",
+	core.Mass:          "mass",
+	core.Teleport:      "teleport",
+	core.Crumble:       "crumble",
+	core.ConveyorUp:    "conveyor up",
+	core.ConveyorDown:  "conveyor down",
+	core.ConveyorLeft:  "conveyor left",
+	core.ConveyorRight: "conveyor right",
+	core.Hill:          "hill",
+	core.Flag:          "flag",
+	core.Goal:          "goal",
+}
+
+// interface check: ebiten.Game
+var _ ebiten.Game = (*Editor)(nil)
+
+// Editor is the graphical map editor.
+// The map is painted with the mouse (left: selected cell type, right: void)
+// and checked with the map linter after each change (see maplint.Lint).
+type Editor struct {
+	ui     *ebitenui.UI
+	header string         // map header, saved unchanged (see core.SplitMap)
+	grid   [][]*core.Cell // [xCol][yRow]
+	paint  byte           // selected cell type
+	report *maplint.Report
+	dirty  bool // the map has changed since the last lint
+
+	// widgets
+	sidebar     *widget.Container
+	palette     map[byte]*widget.Button
+	nameInput   *widget.TextInput
+	widthInput  *widget.TextInput
+	heightInput *widget.TextInput
+	paintLabel  *widget.Label
+	lintLabel   *widget.Label
+	statusLabel *widget.Label
+}
+
+// RunEditor starts the map editor window and loads the map.
+// If the map does not exist, a new map is created.
+//
+// This call is blocking.
+func RunEditor(mapName string) error {
+	e := NewEditor()
+	e.nameInput.InputText = mapName
+	if err := e.Load(mapName); err != nil {
+		e.New(20, 12)
+		e.status("new map %s", mapName)
+	}
+
+	// config window
+	w, h := e.Layout(0, 0)
+	ebiten.SetWindowTitle("Space Bumper - Map Editor")
+	ebiten.SetWindowIcon([]image.Image{resources.Games.Logo})
+	ebiten.SetWindowSize(w, h)
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	ebiten.SetTPS(GameSpeed)
+
+	// run (BLOCKING)
+	return ebiten.RunGame(e)
+}
+
+// NewEditor creates the editor with an empty map (see New and Load).
+func NewEditor() *Editor {
+	e := &Editor{
+		paint:   core.Tile,
+		palette: make(map[byte]*widget.Button),
+	}
+	e.ui = &ebitenui.UI{Container: e.createUI()}
+	e.New(20, 12)
+	e.selectPaint(core.Tile)
+	return e
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// XWidth returns the grid width.
+func (e *Editor) XWidth() int {
+	return len(e.grid)
+}
+
+// YHeight returns the grid height.
+func (e *Editor) YHeight() int {
+	if len(e.grid) == 0 {
+		return 0
+	}
+	return len(e.grid[0])
+}
+
+// Report returns the last lint report (see maplint.Lint).
+func (e *Editor) Report() *maplint.Report {
+	return e.report
+}
+
+// String returns the map in the map file format (header, '---' and the grid lines ending with '|').
+func (e *Editor) String() string {
+	sb := new(strings.Builder)
+	if e.header != "" {
+		sb.WriteString(e.header + "\n" + core.HeaderEnd + "\n")
+	}
+	for yRow := 0; yRow < e.YHeight(); yRow++ {
+		if yRow > 0 {
+			sb.WriteByte('\n')
+		}
+		for xCol := 0; xCol < e.XWidth(); xCol++ {
+			sb.WriteByte(e.grid[xCol][yRow].Type())
+		}
+		sb.WriteByte('|')
+	}
+	return sb.String()
+}
+
+//--------  Setter  --------------------------------------------------------------------------------------------------//
+
+// New replaces the map with an empty map (tiles with a block border) without header.
+func (e *Editor) New(xWidth, yHeight int) {
+	e.header = ""
+	e.grid = nil
+	e.Resize(xWidth, yHeight)
+	for xCol := 0; xCol < xWidth; xCol++ {
+		for yRow := 0; yRow < yHeight; yRow++ {
+			if xCol == 0 || yRow == 0 || xCol == xWidth-1 || yRow == yHeight-1 {
+				e.grid[xCol][yRow].SetType(core.Blocked)
+			}
+		}
+	}
+}
+
+// Load loads the map file (see core.MapPath).
+func (e *Editor) Load(mapName string) error {
+	path := core.MapPath(mapName)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// parse grid (unknown characters are kept and reported by the linter)
+	header, lines := core.SplitMap(b)
+	for len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1] // trailing newline
+	}
+	xWidth := 0
+	for _, l := range lines {
+		if len(l) > xWidth {
+			xWidth = len(l)
+		}
+	}
+	if xWidth == 0 {
+		return fmt.Errorf("empty map %s", path)
+	}
+	e.header = header
+	e.grid = make([][]*core.Cell, xWidth)
+	for xCol := range e.grid {
+		e.grid[xCol] = make([]*core.Cell, len(lines))
+		for yRow, l := range lines {
+			t := byte(core.None)
+			if xCol < len(l) {
+				t = l[xCol]
+			}
+			e.grid[xCol][yRow] = newEditorCell(t, xCol, yRow)
+		}
+	}
+
+	e.widthInput.InputText = strconv.Itoa(e.XWidth())
+	e.heightInput.InputText = strconv.Itoa(e.YHeight())
+	e.status("loaded %s", path)
+	e.lint()
+	return nil
+}
+
+// Save writes the map file (see core.MapPath).
+// New maps are saved in the 'maps' directory, if it exists.
+func (e *Editor) Save(mapName string) error {
+	path := core.MapPath(mapName)
+	if _, err := os.Stat(path); err != nil && !strings.ContainsAny(mapName, `/\`) {
+		if fi, err := os.Stat("maps"); err == nil && fi.IsDir() {
+			path = filepath.Join("maps", path)
+		}
+	}
+	if err := os.WriteFile(path, []byte(e.String()), 0644); err != nil {
+		return err
+	}
+
+	e.lint()
+	if e.report.Errors() > 0 {
+		e.status("saved %s with %d error(s)", path, e.report.Errors())
+	} else {
+		e.status("saved %s", path)
+	}
+	return nil
+}
+
+// Resize changes the grid size. Existing cells are kept, new cells are tiles.
+func (e *Editor) Resize(xWidth, yHeight int) {
+	grid := make([][]*core.Cell, xWidth)
+	for xCol := range grid {
+		grid[xCol] = make([]*core.Cell, yHeight)
+		for yRow := range grid[xCol] {
+			if xCol < e.XWidth() && yRow < e.YHeight() {
+				grid[xCol][yRow] = e.grid[xCol][yRow]
+			} else {
+				grid[xCol][yRow] = core.NewCell(core.Tile, xCol, yRow)
+			}
+		}
+	}
+	e.grid = grid
+
+	e.widthInput.InputText = strconv.Itoa(xWidth)
+	e.heightInput.InputText = strconv.Itoa(yHeight)
+	e.dirty = true
+}
+
+// SetCell sets the cell type. Returns true if the cell has changed.
+func (e *Editor) SetCell(xCol, yRow int, t byte) bool {
+	if xCol < 0 || yRow < 0 || xCol >= e.XWidth() || yRow >= e.YHeight() {
+		return false
+	}
+	c := e.grid[xCol][yRow]
+	if c.Type() == t {
+		return false
+	}
+	c.SetType(t)
+	e.dirty = true
+	return true
+}
+
+//--------  Update  --------------------------------------------------------------------------------------------------//
+
+// Layout returns the grid size plus the sidebar (see ebiten.Game).
+func (e *Editor) Layout(_, _ int) (int, int) {
+	sw, sh := e.sidebar.PreferredSize()
+	h := e.YHeight() * core.CellSize
+	if h < sh {
+		h = sh
+	}
+	if h < editorMinHeight {
+		h = editorMinHeight
+	}
+	return e.XWidth()*core.CellSize + sw, h
+}
+
+// Update paints the cells and updates the UI (see ebiten.Game).
+func (e *Editor) Update() error {
+	e.ui.Update()
+
+	// paint (left: selected type, right: void)
+	x, y := ebiten.CursorPosition()
+	xCol, yRow := x/core.CellSize, y/core.CellSize
+	if x >= 0 && y >= 0 && x < e.XWidth()*core.CellSize {
+		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+			e.SetCell(xCol, yRow, e.paint)
+		} else if ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) {
+			e.SetCell(xCol, yRow, core.None)
+		}
+	}
+
+	// lint after the mouse button is released
+	if e.dirty && !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) && !ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) {
+		e.lint()
+	}
+	return nil
+}
+
+// Draw draws the grid, the lint issues and the sidebar (see ebiten.Game).
+func (e *Editor) Draw(screen *ebiten.Image) {
+	gw, gh := e.XWidth()*core.CellSize, e.YHeight()*core.CellSize
+
+	// DRAW: background image
+	op := new(ebiten.DrawImageOptions)
+	op.GeoM.Scale(float64(gw)/2600.0, float64(gh)/1839.0) // bgImage is 2600px * 1839px
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(resources.Games.Bg, op)
+
+	// DRAW: cells
+	for xCol := range e.grid {
+		for _, c := range e.grid[xCol] {
+			drawCell(screen, c, ebiten.GeoM{})
+		}
+	}
+
+	// DRAW: lint issues (red: error, yellow: warning)
+	if e.report != nil {
+		for _, i := range e.report.Issues {
+			if i.XCol < 0 {
+				continue // whole map
+			}
+			clr := color.RGBA{R: 255, G: 200, A: 90}
+			if i.Severity == maplint.Error {
+				clr = color.RGBA{R: 255, A: 110}
+			}
+			ebitenutil.DrawRect(screen, float64(i.XCol*core.CellSize), float64(i.YRow*core.CellSize), core.CellSize, core.CellSize, clr)
+		}
+	}
+
+	// DRAW: cursor
+	x, y := ebiten.CursorPosition()
+	if x >= 0 && y >= 0 && x < gw && y < gh {
+		xCol, yRow := x/core.CellSize, y/core.CellSize
+		ebitenutil.DrawRect(screen, float64(xCol*core.CellSize), float64(yRow*core.CellSize), core.CellSize, core.CellSize, color.RGBA{R: 255, G: 255, B: 255, A: 60})
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%d,%d", xCol, yRow), xCol*core.CellSize+2, yRow*core.CellSize)
+	}
+
+	// DRAW: sidebar
+	e.ui.Draw(screen)
+}
+
+//--------  UI  ------------------------------------------------------------------------------------------------------//
+
+// createUI creates the sidebar.
+func (e *Editor) createUI() *widget.Container {
+	// grid (left, drawn by Draw) and sidebar (right)
+	root := widget.NewContainer(widget.ContainerOpts.Layout(widget.NewGridLayout(
+		widget.GridLayoutOpts.Columns(2),
+		widget.GridLayoutOpts.Stretch([]bool{true, false}, []bool{true}),
+	)))
+	root.AddChild(widget.NewContainer())
+
+	const padding = 15
+	sidebar := widget.NewContainer(
+		widget.ContainerOpts.BackgroundImage(resources.Uis.Bg),
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+			widget.RowLayoutOpts.Padding(widget.Insets{Left: padding, Right: padding, Top: padding, Bottom: padding}),
+			widget.RowLayoutOpts.Spacing(10),
+		)),
+	)
+	root.AddChild(sidebar)
+	e.sidebar = sidebar
+
+	// title (and min. width)
+	sidebar.AddChild(newLabel("Map Editor", resources.Texts.TitleFace))
+	sidebar.AddChild(widget.NewGraphic(widget.GraphicOpts.Image(ebiten.NewImage(editorSidebar-2*padding, 1))))
+
+	// palette
+	palette := widget.NewContainer(widget.ContainerOpts.Layout(widget.NewGridLayout(
+		widget.GridLayoutOpts.Columns(5),
+		widget.GridLayoutOpts.Spacing(4, 4),
+	)))
+	for _, t := range core.CellTypes {
+		t := t
+		b := widget.NewButton(
+			widget.ButtonOpts.Image(resources.TabBooks.IdleButton),
+			widget.ButtonOpts.Graphic(paletteImage(t)),
+			widget.ButtonOpts.GraphicPadding(widget.Insets{Left: 4, Right: 4, Top: 4, Bottom: 4}),
+			widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+				e.selectPaint(t)
+			}),
+		)
+		e.palette[t] = b
+		palette.AddChild(b)
+	}
+	sidebar.AddChild(palette)
+	e.paintLabel = newLabel("", resources.Texts.SmallFace)
+	sidebar.AddChild(e.paintLabel)
+
+	// size
+	sidebar.AddChild(newLabel("Size (width x height)", resources.Texts.SmallFace))
+	size := newRow()
+	e.widthInput = newTextInput("w")
+	e.heightInput = newTextInput("h")
+	size.AddChild(e.widthInput)
+	size.AddChild(e.heightInput)
+	size.AddChild(newButton("Resize", func() {
+		w, errW := strconv.Atoi(e.widthInput.InputText)
+		h, errH := strconv.Atoi(e.heightInput.InputText)
+		if errW != nil || errH != nil || w < 3 || h < 3 || w > editorMaxSize || h > editorMaxSize {
+			e.status("size must be between 3 and %d", editorMaxSize)
+			return
+		}
+		e.Resize(w, h)
+		e.status("resized to %dx%d", w, h)
+	}))
+	sidebar.AddChild(size)
+
+	// file
+	sidebar.AddChild(newLabel("Map name", resources.Texts.SmallFace))
+	e.nameInput = newTextInput("map name")
+	sidebar.AddChild(e.nameInput)
+	file := newRow()
+	file.AddChild(newButton("Load", func() {
+		if err := e.Load(e.nameInput.InputText); err != nil {
+			e.status("err: %v", err)
+		}
+	}))
+	file.AddChild(newButton("Save", func() {
+		if err := e.Save(e.nameInput.InputText); err != nil {
+			e.status("err: %v", err)
+		}
+	}))
+	file.AddChild(newButton("New", func() {
+		e.New(e.XWidth(), e.YHeight())
+		e.status("new map")
+	}))
+	sidebar.AddChild(file)
+
+	// status and lint
+	e.statusLabel = newLabel("", resources.Texts.SmallFace)
+	sidebar.AddChild(e.statusLabel)
+	e.lintLabel = newLabel("", resources.Texts.SmallFace)
+	sidebar.AddChild(e.lintLabel)
+
+	return root
+}
+
+// selectPaint selects the cell type for painting.
+func (e *Editor) selectPaint(t byte) {
+	e.paint = t
+	for pt, b := range e.palette {
+		if pt == t {
+			b.Image = resources.TabBooks.SelectedButton
+		} else {
+			b.Image = resources.TabBooks.IdleButton
+		}
+	}
+	if e.paintLabel != nil {
+		e.paintLabel.Label = fmt.Sprintf("Paint: %s ('%s'), right click: void", cellNames[t], string(t))
+	}
+}
+
+// lint checks the map and shows the issues (see maplint.Lint).
+func (e *Editor) lint() {
+	e.dirty = false
+	e.report = maplint.Lint(e.nameInput.InputText, []byte(e.String()), 0)
+
+	msg := fmt.Sprintf("Lint: %d error(s), %d warning(s)", e.report.Errors(), e.report.Warnings())
+	for n, i := range e.report.Issues {
+		if n == editorLintLines {
+			msg += fmt.Sprintf("\n... %d more", len(e.report.Issues)-n)
+			break
+		}
+		msg += "\n" + wrap(i.String(), 44)
+	}
+	e.lintLabel.Label = msg
+}
+
+// status shows a message in the sidebar.
+func (e *Editor) status(format string, a ...interface{}) {
+	e.statusLabel.Label = wrap(fmt.Sprintf(format, a...), 44)
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+// newEditorCell creates a cell and keeps unknown types (see core.NewCell).
+// Unknown types are drawn as error image and reported by the linter.
+func newEditorCell(t byte, xCol, yRow int) *core.Cell {
+	c := core.NewCell(core.None, xCol, yRow)
+	c.SetType(t)
+	return c
+}
+
+// paletteImage returns the palette image of the cell type (ground, cell image or void).
+func paletteImage(t byte) *ebiten.Image {
+	img := ebiten.NewImage(core.CellSize, core.CellSize)
+	if t == core.None {
+		img.DrawImage(resources.Games.Bg.SubImage(image.Rect(0, 0, core.CellSize, core.CellSize)).(*ebiten.Image), nil)
+	}
+	drawCell(img, core.NewCell(t, 0, 0), ebiten.GeoM{})
+	return img
+}
+
+// newLabel creates a label with the idle text color.
+func newLabel(text string, face font.Face) *widget.Label {
+	return widget.NewLabel(widget.LabelOpts.Text(text, face, resources.Labels.Text))
+}
+
+// newRow creates a horizontal row container.
+func newRow() *widget.Container {
+	return widget.NewContainer(widget.ContainerOpts.Layout(widget.NewRowLayout(
+		widget.RowLayoutOpts.Direction(widget.DirectionHorizontal),
+		widget.RowLayoutOpts.Spacing(8),
+	)))
+}
+
+// newButton creates a text button.
+func newButton(text string, clicked func()) *widget.Button {
+	return widget.NewButton(
+		widget.ButtonOpts.Image(resources.Buttons.Image),
+		widget.ButtonOpts.Text(text, resources.Buttons.Face, resources.Buttons.Text),
+		widget.ButtonOpts.TextPadding(widget.Insets{Left: 12, Right: 12, Top: 4, Bottom: 4}),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			clicked()
+		}),
+	)
+}
+
+// newTextInput creates a text input (stretched in vertical rows).
+func newTextInput(placeholder string) *widget.TextInput {
+	return widget.NewTextInput(
+		widget.TextInputOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true})),
+		widget.TextInputOpts.Image(resources.TextInputs.Image),
+		widget.TextInputOpts.Color(resources.TextInputs.Color),
+		widget.TextInputOpts.Padding(resources.TextInputs.Padding),
+		widget.TextInputOpts.Face(resources.TextInputs.Face),
+		widget.TextInputOpts.CaretOpts(widget.CaretOpts.Size(resources.TextInputs.Face, 2)),
+		widget.TextInputOpts.Placeholder(placeholder),
+	)
+}
+
+// wrap breaks the text into lines of max. n characters (at spaces).
+func wrap(s string, n int) string {
+	lines := make([]string, 0, 1)
+	line := ""
+	for _, w := range strings.Fields(s) {
+		if line != "" && len(line)+1+len(w) > n {
+			lines = append(lines, line)
+			line = "  " + w
+			continue
+		}
+		if line != "" {
+			line += " "
+		}
+		line += w
+	}
+	return strings.Join(append(lines, line), "\n")
+}
@@ -0,0 +1,354 @@
+package gui
+
+import (
+	"SpaceBumper/core"
+	"SpaceBumper/gui/resources"
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"image/color"
+	"math"
+	"math/rand"
+)
+
+// effect lifetimes (frames, see GameSpeed)
+const (
+	flashLife    = 15 // impact flash
+	sparkLife    = 30 // particles
+	scoreLife    = 60 // floating score text
+	ghostLife    = 30 // fall-and-shrink animation
+	trailLength  = 12 // positions per velocity trail
+	maxParticles = 2000
+)
+
+var (
+	sparkWhite  = color.RGBA{R: 0xff, G: 0xff, B: 0xe0, A: 0xff}
+	sparkWall   = color.RGBA{R: 0xff, G: 0x90, B: 0x30, A: 0xff}
+	sparkStar   = color.RGBA{R: 0xff, G: 0xd7, B: 0x40, A: 0xff}
+	sparkAnti   = color.RGBA{R: 0xc0, G: 0x40, B: 0xff, A: 0xff}
+	sparkShield = color.RGBA{R: 0x60, G: 0xc0, B: 0xff, A: 0xff}
+	scoreGood   = color.RGBA{R: 0x60, G: 0xff, B: 0x60, A: 0xff}
+	scoreBad    = color.RGBA{R: 0xff, G: 0x50, B: 0x50, A: 0xff}
+)
+
+// Effects draws the visual effects of the game events (see core.WorldMap.OnEvent):
+// impact flashes, sparks, floating score texts, the fall-and-shrink animation and velocity trails.
+// The effects are animated per frame and are independent of the game speed.
+type Effects struct {
+	world     *core.WorldMap
+	pixel     *ebiten.Image // white particle
+	flashes   []flash
+	particles []particle
+	texts     []scoreText
+	ghosts    []ghost
+	trails    map[*core.Ship][]trailPoint
+}
+
+// flash is an expanding ring (bump, wall hit).
+type flash struct {
+	x, y  float64
+	size  float64 // final scale of the ring
+	clr   color.RGBA
+	frame int
+}
+
+// particle is a spark that flies and fades out.
+type particle struct {
+	x, y   float64
+	vx, vy float64
+	size   float64
+	clr    color.RGBA
+	frame  int
+	life   int
+}
+
+// scoreText is a floating score change (e.g. '+50').
+type scoreText struct {
+	x, y  float64
+	text  string
+	clr   color.RGBA
+	frame int
+}
+
+// ghost is a ship that falls into the void: it shrinks and spins.
+type ghost struct {
+	ship  *core.Ship
+	x, y  float64
+	angle float64
+	frame int
+}
+
+// trailPoint is a past ship position.
+type trailPoint struct {
+	x, y  float64
+	speed float64
+	boost bool
+}
+
+// NewEffects creates the effects and registers the event listener at the world.
+func NewEffects(world *core.WorldMap) *Effects {
+	pixel := ebiten.NewImage(3, 3)
+	pixel.Fill(color.White)
+
+	e := &Effects{
+		world:  world,
+		pixel:  pixel,
+		trails: make(map[*core.Ship][]trailPoint),
+	}
+	world.OnEvent(e.onEvent)
+	return e
+}
+
+//--------  Update  --------------------------------------------------------------------------------------------------//
+
+// onEvent starts the effects of a game event.
+func (e *Effects) onEvent(ev core.Event) {
+	switch ev.Type {
+	case core.EventBump:
+		a, b := ev.Ship.Position(), ev.Other.Position()
+		x, y := (a.X()+b.X())/2, (a.Y()+b.Y())/2
+		e.flash(x, y, 0.8+ev.Speed/8, sparkWhite)
+		e.sparks(x, y, int(ev.Speed*2), 1+ev.Speed/4, sparkWhite)
+		e.score(ev.Ship, ev.Delta)
+		e.score(ev.Other, ev.OtherDelta)
+	case core.EventWallHit:
+		pos := ev.Ship.Position()
+		e.flash(pos.X(), pos.Y(), 0.5+ev.Speed/12, sparkWall)
+		e.sparks(pos.X(), pos.Y(), int(ev.Speed), 1+ev.Speed/5, sparkWall)
+		e.score(ev.Ship, ev.Delta)
+	case core.EventFall:
+		pos := ev.Ship.Position()
+		e.ghosts = append(e.ghosts, ghost{ship: ev.Ship, x: pos.X(), y: pos.Y(), angle: ev.Ship.Angle()})
+		delete(e.trails, ev.Ship)
+		e.score(ev.Ship, ev.Delta)
+	case core.EventKnockOut:
+		e.score(ev.Ship, ev.Delta)
+	case core.EventObjective:
+		if ev.CellType != core.Hill { // no score text for the continuous king of the hill points
+			e.score(ev.Ship, ev.Delta)
+		}
+	case core.EventPickup:
+		clr := sparkShield
+		switch ev.CellType {
+		case core.Star:
+			clr = sparkStar
+		case core.Anti:
+			clr = sparkAnti
+		}
+		c := ev.Cell.Center()
+		e.sparks(c.X(), c.Y(), 16, 1.5, clr)
+		e.flash(c.X(), c.Y(), 1.2, clr)
+		e.score(ev.Ship, ev.Delta)
+	}
+}
+
+// Update animates all effects and records the velocity trails (once per frame).
+func (e *Effects) Update(g *Game) {
+
+	// flashes
+	flashes := e.flashes[:0]
+	for _, f := range e.flashes {
+		if f.frame++; f.frame < flashLife {
+			flashes = append(flashes, f)
+		}
+	}
+	e.flashes = flashes
+
+	// particles (with drag)
+	particles := e.particles[:0]
+	for _, p := range e.particles {
+		p.x += p.vx
+		p.y += p.vy
+		p.vx *= 0.92
+		p.vy *= 0.92
+		if p.frame++; p.frame < p.life {
+			particles = append(particles, p)
+		}
+	}
+	e.particles = particles
+
+	// floating score texts
+	texts := e.texts[:0]
+	for _, t := range e.texts {
+		t.y -= 0.6
+		if t.frame++; t.frame < scoreLife {
+			texts = append(texts, t)
+		}
+	}
+	e.texts = texts
+
+	// falling ships
+	ghosts := e.ghosts[:0]
+	for _, gh := range e.ghosts {
+		gh.angle += 0.25
+		if gh.frame++; gh.frame < ghostLife {
+			ghosts = append(ghosts, gh)
+		}
+	}
+	e.ghosts = ghosts
+
+	// velocity trails (interpolated positions, a jump resets the trail)
+	for _, s := range e.world.Players() {
+		if !s.IsAlive() {
+			delete(e.trails, s)
+			continue
+		}
+		pos := g.position(s)
+		p := trailPoint{x: pos.X(), y: pos.Y(), speed: s.Velocity().Length(), boost: touches(s, core.Boost)}
+		t := e.trails[s]
+		if n := len(t); n > 0 && math.Hypot(t[n-1].x-p.x, t[n-1].y-p.y) > core.CellSize {
+			t = t[:0]
+		}
+		t = append(t, p)
+		if len(t) > trailLength {
+			t = t[len(t)-trailLength:]
+		}
+		e.trails[s] = t
+	}
+}
+
+// Falling returns true while the fall-and-shrink animation of the ship runs.
+// The respawned ship is hidden until the animation ends.
+func (e *Effects) Falling(s *core.Ship) bool {
+	for _, gh := range e.ghosts {
+		if gh.ship == s {
+			return true
+		}
+	}
+	return false
+}
+
+//--------  Draw  ----------------------------------------------------------------------------------------------------//
+
+// DrawUnder draws the effects below the ships (velocity trails).
+func (e *Effects) DrawUnder(screen *ebiten.Image, cam ebiten.GeoM) {
+	for s, t := range e.trails {
+		rgb := s.RGB()
+		for i, p := range t {
+			if p.speed < 2 {
+				continue // no trail for slow ships
+			}
+			age := float64(i+1) / float64(len(t)) // 0 = old, 1 = new
+			alpha := age * math.Min(1, p.speed/12) * 0.6
+			clr := rgb
+			if p.boost {
+				clr = sparkWall
+			}
+			e.drawPixel(screen, cam, p.x, p.y, 1+age*2, clr, alpha)
+		}
+	}
+}
+
+// DrawOver draws the effects above the ships (flashes, particles, falling ships and score texts).
+func (e *Effects) DrawOver(screen *ebiten.Image, camera *Camera) {
+	cam := camera.GeoM()
+
+	// falling ships
+	for _, gh := range e.ghosts {
+		progress := float64(gh.frame) / ghostLife
+		img := shipImage(gh.ship)
+		w, h := img.Size()
+		scale := gh.ship.Radius() / core.CellRadius * (1 - progress)
+
+		op := new(ebiten.DrawImageOptions)
+		op.GeoM.Translate(-float64(w)/2, -float64(h)/2)
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Rotate(gh.angle + 4.71239) // add 270° to align the ship image (see Game.Draw)
+		op.GeoM.Translate(gh.x, gh.y)
+		op.GeoM.Concat(cam)
+		op.ColorM.Scale(1, 1, 1, 1-progress)
+		op.Filter = ebiten.FilterLinear
+		screen.DrawImage(img, op)
+		if img == resources.Games.Ship {
+			rgb := gh.ship.RGB()
+			op.ColorM.Scale(float64(rgb.R)/255, float64(rgb.G)/255, float64(rgb.B)/255, 1)
+			screen.DrawImage(resources.Games.Paint, op)
+		}
+	}
+
+	// flashes (expanding ring)
+	for _, f := range e.flashes {
+		progress := float64(f.frame) / flashLife
+		scale := f.size * (0.3 + 0.7*progress)
+
+		op := new(ebiten.DrawImageOptions)
+		op.GeoM.Translate(-core.CellRadius, -core.CellRadius)
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Translate(f.x, f.y)
+		op.GeoM.Concat(cam)
+		op.ColorM.Scale(float64(f.clr.R)/255, float64(f.clr.G)/255, float64(f.clr.B)/255, 1-progress)
+		op.Filter = ebiten.FilterLinear
+		screen.DrawImage(resources.Games.Ring, op)
+	}
+
+	// particles
+	for _, p := range e.particles {
+		e.drawPixel(screen, cam, p.x, p.y, p.size, p.clr, 1-float64(p.frame)/float64(p.life))
+	}
+
+	// score texts (screen coordinates, not scaled)
+	face := resources.Fonts.ToolTipFace
+	for _, t := range e.texts {
+		x, y := camera.WorldToScreen(t.x, t.y)
+		b := text.BoundString(face, t.text)
+		text.Draw(screen, t.text, face, int(x)-b.Dx()/2, int(y), fade(t.clr, 1-float64(t.frame)/scoreLife))
+	}
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+// flash starts an impact flash.
+func (e *Effects) flash(x, y, size float64, clr color.RGBA) {
+	e.flashes = append(e.flashes, flash{x: x, y: y, size: size, clr: clr})
+}
+
+// sparks starts n particles in random directions (4 to 30).
+func (e *Effects) sparks(x, y float64, n int, speed float64, clr color.RGBA) {
+	n = int(math.Max(4, math.Min(30, float64(n))))
+	for i := 0; i < n && len(e.particles) < maxParticles; i++ {
+		angle := rand.Float64() * 2 * math.Pi
+		v := speed * (0.5 + rand.Float64())
+		e.particles = append(e.particles, particle{
+			x: x, y: y,
+			vx:   math.Cos(angle) * v,
+			vy:   math.Sin(angle) * v,
+			size: 1 + rand.Float64()*1.5,
+			clr:  clr,
+			life: sparkLife/2 + rand.Intn(sparkLife),
+		})
+	}
+}
+
+// score starts a floating score text above the ship (no text if the score has not changed).
+func (e *Effects) score(s *core.Ship, delta int) {
+	if s == nil || delta == 0 {
+		return
+	}
+	clr := scoreGood
+	if delta < 0 {
+		clr = scoreBad
+	}
+	pos := s.Position()
+	e.texts = append(e.texts, scoreText{x: pos.X(), y: pos.Y() - core.CellRadius, text: fmt.Sprintf("%+d", delta), clr: clr})
+}
+
+// drawPixel draws a particle (size in world pixels) with the alpha factor.
+func (e *Effects) drawPixel(screen *ebiten.Image, cam ebiten.GeoM, x, y, size float64, clr color.RGBA, alpha float64) {
+	op := new(ebiten.DrawImageOptions)
+	op.GeoM.Translate(-1.5, -1.5) // pixel image 3x3
+	op.GeoM.Scale(size, size)
+	op.GeoM.Translate(x, y)
+	op.GeoM.Concat(cam)
+	op.ColorM.Scale(float64(clr.R)/255, float64(clr.G)/255, float64(clr.B)/255, alpha)
+	screen.DrawImage(e.pixel, op)
+}
+
+// touches returns true if the ship touches a cell of the type.
+func touches(s *core.Ship, t byte) bool {
+	for _, c := range s.TouchingCells() {
+		if c.Type() == t {
+			return true
+		}
+	}
+	return false
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"image"
+	"math"
 	"sort"
 )
 
@@ -20,35 +22,71 @@ type Game struct {
 	screenWidth  int
 	screenHeight int
 	world        *core.WorldMap
-	callUpdate   bool
+	clock        *core.Clock // optional
+	camera       *Camera
+	hud          *HUD
+	effects      *Effects
+	debug        *Debug
+	drawings     *Drawings
+	locals       map[int]Bindings // local players: player id -> input devices (see SetLocals)
 }
 
 // RunGame starts a GUI window and displays the specified world.
-// The callUpdate option activates the core.WorldMap Update() call with 60 Ticks per second.
-// Do not activate this option if the update is done externally.
+// The optional clock drives the core.WorldMap Update() calls with a fixed timestep
+// and the ships are interpolated between the last two ticks.
+// Set clock to nil if the update is done externally.
+// The locals are the player ids of the local players and their input devices (see AddLocalPlayers).
 //
 // This call is blocking.
-func RunGame(title string, world *core.WorldMap, callUpdate bool) error {
+func RunGame(title string, world *core.WorldMap, clock *core.Clock, locals map[int]Bindings) error {
 
 	// config game
-	game := &Game{
-		screenWidth:  world.XWidth() * core.CellSize,  // cell image 40x40
-		screenHeight: world.YHeight() * core.CellSize, // cell image 40x40
-		world:        world,
-		callUpdate:   callUpdate,
-	}
+	game := NewGame(world, clock)
+	game.SetLocals(locals)
 
 	// config window
 	ebiten.SetWindowTitle(title)
 	ebiten.SetWindowIcon([]image.Image{resources.Games.Logo})
 	ebiten.SetWindowSize(game.screenWidth, game.screenHeight)
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
-	ebiten.SetTPS(GameSpeed) // default: 60 ticks per second
+	ebiten.SetTPS(GameSpeed) // default: 60 frames per second (the world speed is set by the clock)
 
 	// run (BLOCKING)
 	return ebiten.RunGame(game)
 }
 
+// NewGame creates the game screen of the world (see RunGame).
+// The optional clock drives the world updates (nil if the update is done externally).
+// The window shows the whole map 1:1 and is scaled down if the map does not fit the monitor (see Camera).
+func NewGame(world *core.WorldMap, clock *core.Clock) *Game {
+	worldWidth := world.XWidth() * core.CellSize   // cell image 40x40
+	worldHeight := world.YHeight() * core.CellSize // cell image 40x40
+	w, h := windowSize(worldWidth, worldHeight)
+	return &Game{
+		screenWidth:  w,
+		screenHeight: h,
+		world:        world,
+		clock:        clock,
+		camera:       NewCamera(worldWidth, worldHeight, w, h),
+		hud:          NewHUD(world),
+		effects:      NewEffects(world),
+		debug:        NewDebug(world),
+		drawings:     NewDrawings(world),
+		locals:       map[int]Bindings{0: DefaultBindings()},
+	}
+}
+
+// SetLocals sets the local players (player id -> input devices, see AddLocalPlayers).
+// The default is the first player with all devices (see DefaultBindings).
+func (g *Game) SetLocals(locals map[int]Bindings) {
+	g.locals = locals
+}
+
+// Camera returns the camera (zoom, pan, follow a ship).
+func (g *Game) Camera() *Camera {
+	return g.camera
+}
+
 //--------------------------------------------------------------------------------------------------------------------//
 
 // Layout accepts a native outside size in device-independent pixels and returns the game's logical screen
@@ -68,7 +106,13 @@ func RunGame(title string, world *core.WorldMap, callUpdate bool) error {
 //
 // You can return a fixed screen size if you don't care, or you can also return a calculated screen size
 // adjusted with the given outside size.
-func (g *Game) Layout(_, _ int) (int, int) {
+//
+// The screen has the size of the window (see Camera).
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	if outsideWidth > 0 && outsideHeight > 0 {
+		g.screenWidth, g.screenHeight = outsideWidth, outsideHeight
+		g.camera.SetViewport(outsideWidth, outsideHeight)
+	}
 	return g.screenWidth, g.screenHeight
 }
 
@@ -83,31 +127,33 @@ func (g *Game) Layout(_, _ int) (int, int) {
 // or more for one frame. The frequency is determined by the current TPS (tick-per-second).
 func (g *Game) Update() error {
 
-	// player control
-	id := 0
-	ship, err := g.world.Player(id)
-	if err == nil && ship.IsAlive() && ship.Remote() == nil {
-		// keys
-		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-			// cursor position
-			x, y := ebiten.CursorPosition()
-			// ship position
-			pos := ship.Position()
-			// calc acceleration vector
-			cmd := core.NewVector((float64(x)-pos.X())/100, (float64(y)-pos.Y())/100)
-			// set move command
-			ship.Move(cmd)
-		} else {
-			// reset move command
-			ship.Move(new(core.Vector))
+	// camera (zoom, pan, follow)
+	g.camera.Update(g)
+
+	// local player control (mouse, keyboard, gamepad)
+	for id, b := range g.locals {
+		ship, err := g.world.Player(id)
+		if err == nil && ship.IsAlive() && ship.Remote() == nil && ship.Controller() == nil {
+			b.Control(ship, g.camera)
 		}
 	}
 
-	// call world update
-	if g.callUpdate {
-		g.world.Update()
+	// call world update (fixed timestep)
+	if g.clock != nil {
+		g.clock.Step()
 	}
 
+	// animate the effects (once per frame)
+	g.effects.Update(g)
+
+	// debug overlay
+	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+		g.debug.Toggle()
+	}
+
+	// bot drawings (toggle per player)
+	g.drawings.Update()
+
 	// return
 	return nil
 }
@@ -123,66 +169,27 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	op.Filter = ebiten.FilterLinear                                              // Specify linear filter.
 	screen.DrawImage(resources.Games.Bg, op)
 
-	// DRAW: cell images (Map)
-	xWidth := g.world.XWidth()
-	yHeight := g.world.YHeight()
-	for xCol := 0; xCol < xWidth; xCol++ {
-		for yRow := 0; yRow < yHeight; yRow++ {
-			cell := g.world.Cell(xCol, yRow)
-
-			// prepare image
-			op := new(ebiten.DrawImageOptions)
-			op.GeoM.Translate(float64(xCol*core.CellSize), float64(yRow*core.CellSize)) // cell image 40x40
-			op.Filter = ebiten.FilterLinear                                             // Specify linear filter.
-
-			// draw cell
-			switch cell.Type() {
-			case core.Blocked:
-				screen.DrawImage(resources.Games.Tile, op)
-				screen.DrawImage(resources.Games.Block, op)
-			case core.Boost:
-				screen.DrawImage(resources.Games.Tile, op)
-				screen.DrawImage(resources.Games.Boost, op)
-			case core.Slow:
-				screen.DrawImage(resources.Games.Tile, op)
-				screen.DrawImage(resources.Games.Slow, op)
-			case core.Tile:
-				screen.DrawImage(resources.Games.Tile, op)
-			case core.Star:
-				screen.DrawImage(resources.Games.Tile, op)
-				screen.DrawImage(resources.Games.Star, op)
-			case core.Anti:
-				screen.DrawImage(resources.Games.Tile, op)
-				screen.DrawImage(resources.Games.Anti, op)
-			case core.Spawn:
-				screen.DrawImage(resources.Games.Tile, op)
-				screen.DrawImage(resources.Games.Spawn, op)
-			case core.None:
-				// draw nothing (= background image)
-			default:
-				screen.DrawImage(resources.Games.Error, op) // ERROR
-			}
+	// DRAW: cell images (Map, only the visible cells)
+	cam := g.camera.GeoM()
+	x0, y0, x1, y1 := g.camera.Visible()
+	for xCol := x0; xCol <= x1; xCol++ {
+		for yRow := y0; yRow <= y1; yRow++ {
+			drawCell(screen, g.world.Cell(xCol, yRow), cam)
 		}
 	}
 
+	// DRAW: velocity trails
+	g.effects.DrawUnder(screen, cam)
+
 	// DRAW: ships
 	for _, s := range g.world.Players() {
+		if g.effects.Falling(s) {
+			continue // respawn after the fall animation
+		}
 		op := new(ebiten.DrawImageOptions)
 
 		// get ship image
-		var sImg *ebiten.Image
-		switch s.Color() {
-		case "red":
-			sImg = resources.Games.Red
-		case "blue":
-			sImg = resources.Games.Blue
-		case "green":
-			sImg = resources.Games.Green
-		case "orange":
-			sImg = resources.Games.Orange
-		default:
-			sImg = resources.Games.Error
-		}
+		sImg := shipImage(s)
 
 		// Move the image's center to the screen's upper-left corner.
 		// This is a preparation for rotating. When geometry matrices are applied,
@@ -190,6 +197,11 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		w, h := sImg.Size()
 		op.GeoM.Translate(-float64(w)/2, -float64(h)/2)
 
+		// The ship is scaled to its radius (see core.ShipClass).
+		// Ships with the mass power-up (see core.Mass) are drawn larger.
+		scale := s.Radius() / core.CellRadius * (s.Mass()/s.Class().Mass/2 + 0.5)
+		op.GeoM.Scale(scale, scale)
+
 		// Rotate the image. As a result, the anchor point of this rotate is
 		// the center of the image.
 		//   90° × π/180 =1,571 rad
@@ -197,43 +209,205 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		op.GeoM.Rotate(angle)
 
 		// Move the image to the final position.
-		pos := s.Position()
+		pos := g.position(s)
 		op.GeoM.Translate(pos.X(), pos.Y())
+		op.GeoM.Concat(cam)
 
 		// draw ship
 		op.Filter = ebiten.FilterLinear // Specify linear filter.
 		screen.DrawImage(sImg, op)
+		if sImg == resources.Games.Ship {
+			rgb := s.RGB()
+			op.ColorM.Scale(float64(rgb.R)/255, float64(rgb.G)/255, float64(rgb.B)/255, 1)
+			screen.DrawImage(resources.Games.Paint, op)
+		}
+
+		// draw team marker (see core.Ship.Team)
+		if s.Team() != "" {
+			tc := core.TeamColor(s.Team())
+			top := new(ebiten.DrawImageOptions)
+			top.GeoM.Translate(-core.CellRadius, -core.CellRadius)
+			top.GeoM.Scale(1.2, 1.2)
+			top.GeoM.Translate(pos.X(), pos.Y())
+			top.GeoM.Concat(cam)
+			top.ColorM.Scale(float64(tc.R)/255, float64(tc.G)/255, float64(tc.B)/255, 1)
+			top.Filter = ebiten.FilterLinear
+			screen.DrawImage(resources.Games.Ring, top)
+		}
+
+		// draw shield (see core.Shield)
+		if s.Shield() > 0 {
+			sop := new(ebiten.DrawImageOptions)
+			sop.GeoM.Translate(-core.CellRadius, -core.CellRadius)
+			sop.GeoM.Scale(1.5, 1.5)
+			sop.GeoM.Translate(pos.X(), pos.Y())
+			sop.GeoM.Concat(cam)
+			sop.ColorM.Scale(1, 1, 1, 0.4)
+			sop.Filter = ebiten.FilterLinear
+			screen.DrawImage(resources.Games.Shield, sop)
+		}
 
-		// TEXT: debug messages
-		iteration, endtime, maxUpdateTime := g.world.Stats()
-		msg := fmt.Sprintf("\n  round=%d/%d, maxUpdateTime=%v\n", iteration, endtime, maxUpdateTime)
-		for i, p := range sortPlayer(g.world.Players()) {
-			if p != nil {
-				if p.IsAlive() {
-					msg += fmt.Sprintf("  %d. %s: %d (Speed %.2f -> %.2f)\n", i+1, p.Name(), p.Score(), p.Acceleration().Length(), p.Velocity().Length())
-				} else {
-					msg += fmt.Sprintf("  %d. %s: dead\n", i+1, p.Name())
-				}
-			}
+		// draw carried flag (see core.CaptureTheFlag)
+		if ctf, ok := g.world.GameMode().(*core.CaptureTheFlag); ok && ctf.Carrying(s) {
+			fop := new(ebiten.DrawImageOptions)
+			fop.GeoM.Scale(0.6, 0.6)
+			fop.GeoM.Translate(pos.X(), pos.Y()-core.CellSize)
+			fop.GeoM.Concat(cam)
+			fop.Filter = ebiten.FilterLinear
+			screen.DrawImage(resources.Games.Flag, fop)
 		}
-		ebitenutil.DebugPrint(screen, msg)
 
-		// TEXT: Name
+		// TEXT: Name and score (screen position, not scaled)
+		sx, sy := g.camera.WorldToScreen(pos.X(), pos.Y())
+		radius := core.CellRadius * g.camera.Zoom()
 		name := fmt.Sprintf("%s", s.Name())
-		namePosX := pos.X() - (6 / 2 * float64(len(name)))
-		namePosY := pos.Y() + 5 + core.CellRadius
+		if s.Team() != "" {
+			name = fmt.Sprintf("%s [%s]", s.Name(), s.Team())
+		}
+		namePosX := sx - (6 / 2 * float64(len(name)))
+		namePosY := sy + 5 + radius
 		ebitenutil.DebugPrintAt(screen, name, int(namePosX), int(namePosY))
 
 		// TEXT: Score
 		score := fmt.Sprintf("%d", s.Score())
-		scorePosX := pos.X() - (6 / 2 * float64(len(score)))
-		scorePosY := pos.Y() - 8
+		scorePosX := sx - (6 / 2 * float64(len(score)))
+		scorePosY := sy - 8
 		ebitenutil.DebugPrintAt(screen, score, int(scorePosX), int(scorePosY))
 	}
+
+	// DRAW: bot drawings (DRAW command)
+	g.drawings.Draw(screen, g.camera)
+
+	// DRAW: flashes, sparks, falling ships and score texts
+	g.effects.DrawOver(screen, g.camera)
+
+	// DRAW: scoreboard, time and kill feed
+	g.hud.Draw(screen)
+
+	// DRAW: debug overlay (F3)
+	g.debug.Draw(screen, g.camera)
 }
 
 //--------------------------------------------------------------------------------------------------------------------//
 
+// position returns the interpolated ship position (see core.Clock).
+func (g *Game) position(s *core.Ship) *core.Vector {
+	if g.clock == nil {
+		return s.Position()
+	}
+	return g.clock.Position(s)
+}
+
+// shipImage returns the image of the ship color.
+// Hex colors use the neutral ship that is drawn with the tinted paint layer (see core.ParseColor).
+func shipImage(s *core.Ship) *ebiten.Image {
+	switch s.Color() {
+	case "red":
+		return resources.Games.Red
+	case "blue":
+		return resources.Games.Blue
+	case "green":
+		return resources.Games.Green
+	case "orange":
+		return resources.Games.Orange
+	default:
+		return resources.Games.Ship
+	}
+}
+
+// drawCell draws the cell image at the cell position.
+// The camera transforms the world to the screen (see Camera.GeoM).
+func drawCell(screen *ebiten.Image, cell *core.Cell, cam ebiten.GeoM) {
+	if cell.Type() == core.None {
+		return // draw nothing (= background image)
+	}
+
+	// prepare image
+	op := new(ebiten.DrawImageOptions)
+	op.GeoM.Translate(float64(cell.XCol()*core.CellSize), float64(cell.YRow()*core.CellSize)) // cell image 40x40
+	op.Filter = ebiten.FilterLinear                                                           // Specify linear filter.
+	op.GeoM.Concat(cam)                                                                       // camera
+
+	// draw ground
+	img := cellImage(cell.Type())
+	if img == resources.Games.Error {
+		screen.DrawImage(img, op) // ERROR
+		return
+	}
+	screen.DrawImage(resources.Games.Tile, op)
+
+	// draw cell
+	switch cell.Type() {
+	case core.Tile:
+		// ground only
+	case core.ConveyorUp, core.ConveyorDown, core.ConveyorLeft, core.ConveyorRight:
+		// rotate the conveyor image (points right) around the cell center
+		cop := new(ebiten.DrawImageOptions)
+		cop.GeoM.Translate(-core.CellRadius, -core.CellRadius)
+		cop.GeoM.Rotate(cell.Conveyor().Angle())
+		cop.GeoM.Translate(float64(cell.XCol()*core.CellSize)+core.CellRadius, float64(cell.YRow()*core.CellSize)+core.CellRadius)
+		cop.GeoM.Concat(cam)
+		cop.Filter = ebiten.FilterLinear
+		screen.DrawImage(img, cop)
+	default:
+		screen.DrawImage(img, op)
+	}
+}
+
+// windowSize returns the window size of the world: 1:1 or scaled down to 90% of the monitor.
+func windowSize(worldWidth, worldHeight int) (int, int) {
+	mw, mh := ebiten.ScreenSizeInFullscreen()
+	if mw <= 0 || mh <= 0 {
+		return worldWidth, worldHeight
+	}
+	scale := math.Min(1, math.Min(0.9*float64(mw)/float64(worldWidth), 0.9*float64(mh)/float64(worldHeight)))
+	return int(float64(worldWidth) * scale), int(float64(worldHeight) * scale)
+}
+
+// toFloat converts a position to float64.
+func toFloat(x, y int) (float64, float64) {
+	return float64(x), float64(y)
+}
+
+// cellImage returns the image of the cell type that is drawn on the ground (Tile).
+// The void (None) and the ground return nil, unknown types the error image.
+func cellImage(t byte) *ebiten.Image {
+	switch t {
+	case core.None, core.Tile:
+		return nil
+	case core.Blocked:
+		return resources.Games.Block
+	case core.Boost:
+		return resources.Games.Boost
+	case core.Slow:
+		return resources.Games.Slow
+	case core.Star:
+		return resources.Games.Star
+	case core.Anti:
+		return resources.Games.Anti
+	case core.Spawn:
+		return resources.Games.Spawn
+	case core.Shield:
+		return resources.Games.Shield
+	case core.Mass:
+		return resources.Games.Mass
+	case core.Teleport:
+		return resources.Games.Teleport
+	case core.Crumble:
+		return resources.Games.Crumble
+	case core.ConveyorUp, core.ConveyorDown, core.ConveyorLeft, core.ConveyorRight:
+		return resources.Games.Conveyor
+	case core.Hill:
+		return resources.Games.Hill
+	case core.Flag:
+		return resources.Games.Flag
+	case core.Goal:
+		return resources.Games.Goal
+	default:
+		return resources.Games.Error
+	}
+}
+
 func sortPlayer(in []*core.Ship) []*core.Ship {
 	// clone
 	out := make([]*core.Ship, 0, len(in))
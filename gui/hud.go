@@ -0,0 +1,302 @@
+package gui
+
+import (
+	"SpaceBumper/core"
+	"SpaceBumper/gui/resources"
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"image/color"
+	"math"
+)
+
+const (
+	hudPadding  = 8                // distance to the screen border and inside the panels
+	hudRow      = 22               // height of a scoreboard row
+	hudSpeedMax = 15.0             // speed of a full speed bar
+	feedSize    = 6                // max. number of kill feed lines
+	feedTime    = 5 * core.TPS     // ticks a kill feed line is shown
+	feedFade    = 1 * core.TPS     // ticks a kill feed line fades out
+	hudStarSize = 16.0             // star icon size (pixels)
+	hudBarWidth = 60.0             // speed bar width (pixels)
+	hudNameMax  = 18               // max. name length (runes)
+	hudAlpha    = 0xb0             // panel transparency
+	hudSpacing  = hudPadding * 1.5 // space between the scoreboard columns
+)
+
+var (
+	hudPanel = color.RGBA{A: hudAlpha}
+	hudText  = color.RGBA{R: 0xe8, G: 0xe8, B: 0xe8, A: 0xff}
+	hudDim   = color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}
+	hudGold  = color.RGBA{R: 0xff, G: 0xd7, B: 0x40, A: 0xff}
+	hudRed   = color.RGBA{R: 0xff, G: 0x50, B: 0x50, A: 0xff}
+)
+
+// HUD draws the overlay of the game screen:
+// the ranked scoreboard, the remaining time, the game state and the kill feed.
+// The kill feed is driven by the game events of the world (see core.WorldMap.OnEvent).
+type HUD struct {
+	world *core.WorldMap
+	feed  []feedLine // newest last
+}
+
+// feedLine is a kill feed message.
+type feedLine struct {
+	text      string
+	clr       color.RGBA
+	iteration uint64
+	ship      *core.Ship // the ship the message is about (see add)
+	knockOut  bool
+}
+
+// NewHUD creates the overlay and registers the event listener at the world.
+func NewHUD(world *core.WorldMap) *HUD {
+	h := &HUD{world: world}
+	world.OnEvent(h.onEvent)
+	return h
+}
+
+//--------  Update  --------------------------------------------------------------------------------------------------//
+
+// onEvent adds the knock-outs, falls and objectives to the kill feed.
+func (h *HUD) onEvent(e core.Event) {
+	switch e.Type {
+	case core.EventKnockOut:
+		h.add(feedLine{
+			text:     fmt.Sprintf("%s knocked out %s", e.Ship.Name(), e.Other.Name()),
+			clr:      e.Ship.RGB(),
+			ship:     e.Other,
+			knockOut: true,
+		}, e.Iteration)
+	case core.EventFall:
+		// the fall of a knocked out ship is already in the feed
+		if n := len(h.feed); n > 0 && h.feed[n-1].knockOut && h.feed[n-1].ship == e.Ship && h.feed[n-1].iteration == e.Iteration {
+			return
+		}
+		h.add(feedLine{text: fmt.Sprintf("%s fell into the void", e.Ship.Name()), clr: e.Ship.RGB(), ship: e.Ship}, e.Iteration)
+	case core.EventObjective:
+		if e.CellType == core.Hill {
+			return // continuous king of the hill points (see the scoreboard)
+		}
+		h.add(feedLine{text: fmt.Sprintf("%s scored %+d", e.Ship.Name(), e.Delta), clr: e.Ship.RGB(), ship: e.Ship}, e.Iteration)
+	}
+}
+
+// add appends a line to the kill feed and removes the oldest lines.
+func (h *HUD) add(l feedLine, iteration uint64) {
+	l.iteration = iteration
+	h.feed = append(h.feed, l)
+	if len(h.feed) > feedSize {
+		h.feed = h.feed[len(h.feed)-feedSize:]
+	}
+}
+
+//--------  Draw  ----------------------------------------------------------------------------------------------------//
+
+// Draw draws the overlay on the screen (not transformed by the camera).
+func (h *HUD) Draw(screen *ebiten.Image) {
+	h.drawScoreboard(screen)
+	h.drawTime(screen)
+	h.drawFeed(screen)
+	h.drawGameOver(screen)
+}
+
+// drawScoreboard draws the ranked players (top left):
+// rank, color, name [team], score, collected stars and a speed bar.
+// Team scores and the king of the hill are listed below.
+func (h *HUD) drawScoreboard(screen *ebiten.Image) {
+	face := resources.Fonts.ToolTipFace
+	players := sortPlayer(h.world.Players())
+
+	// column widths
+	nameWidth, scoreWidth := 0, 0
+	for _, p := range players {
+		nameWidth = maxInt(nameWidth, text.BoundString(face, playerLabel(p)).Dx())
+		scoreWidth = maxInt(scoreWidth, text.BoundString(face, fmt.Sprint(p.Score())).Dx())
+	}
+	rankWidth := text.BoundString(face, "00.").Dx()
+
+	// extra lines
+	var extra []string
+	for _, t := range h.world.TeamScores() {
+		extra = append(extra, fmt.Sprintf("Team %s: %d", t.Team, t.Score))
+	}
+	if koth, ok := h.world.GameMode().(*core.KingOfTheHill); ok {
+		for _, king := range koth.Kings() {
+			if king != nil {
+				extra = append(extra, fmt.Sprintf("King of the hill: %s", king.Name()))
+			}
+		}
+	}
+
+	// panel
+	xRank := float64(hudPadding * 2)
+	xColor := xRank + float64(rankWidth) + hudSpacing
+	xName := xColor + 10 + hudSpacing
+	xScore := xName + float64(nameWidth) + hudSpacing
+	xStars := xScore + float64(scoreWidth) + hudSpacing
+	xBar := xStars + hudStarSize + 24 + hudSpacing
+	width := xBar + hudBarWidth
+	height := float64(hudRow*(len(players)+len(extra)) + hudPadding*2)
+	ebitenutil.DrawRect(screen, hudPadding, hudPadding, width, height, hudPanel)
+
+	// players
+	y := float64(hudPadding * 2)
+	for i, p := range players {
+		clr := hudText
+		if !p.IsAlive() {
+			clr = hudDim
+		}
+		base := int(y) + hudRow - 7 // text baseline
+
+		text.Draw(screen, fmt.Sprintf("%d.", i+1), face, int(xRank), base, clr)
+		ebitenutil.DrawRect(screen, xColor, y+4, 10, hudRow-8, p.RGB())
+		text.Draw(screen, playerLabel(p), face, int(xName), base, clr)
+		score := fmt.Sprint(p.Score())
+		text.Draw(screen, score, face, int(xScore)+scoreWidth-text.BoundString(face, score).Dx(), base, clr)
+
+		// stars
+		op := new(ebiten.DrawImageOptions)
+		w, _ := resources.Games.Star.Size()
+		op.GeoM.Scale(hudStarSize/float64(w), hudStarSize/float64(w))
+		op.GeoM.Translate(xStars, y+(hudRow-hudStarSize)/2)
+		op.Filter = ebiten.FilterLinear
+		screen.DrawImage(resources.Games.Star, op)
+		text.Draw(screen, fmt.Sprint(p.Stars()), face, int(xStars+hudStarSize+4), base, clr)
+
+		// speed bar
+		if p.IsAlive() {
+			speed := math.Min(1, p.Velocity().Length()/hudSpeedMax)
+			ebitenutil.DrawRect(screen, xBar, y+8, hudBarWidth, hudRow-14, hudDim)
+			ebitenutil.DrawRect(screen, xBar, y+8, hudBarWidth*speed, hudRow-14, speedColor(speed))
+		} else {
+			text.Draw(screen, "dead", face, int(xBar), base, hudRed)
+		}
+		y += hudRow
+	}
+
+	// team scores and king
+	for _, l := range extra {
+		text.Draw(screen, l, face, int(xRank), int(y)+hudRow-7, hudGold)
+		y += hudRow
+	}
+}
+
+// drawTime draws the remaining time (top center).
+// Games without end time show the elapsed time.
+func (h *HUD) drawTime(screen *ebiten.Image) {
+	face := resources.Fonts.TitleFace
+	iteration, endtime, _ := h.world.Stats()
+
+	msg := formatTicks(iteration)
+	switch {
+	case h.world.Overtime():
+		msg = fmt.Sprintf("OVERTIME (%s)", h.world.TieBreak())
+	case endtime != math.MaxUint64 && endtime > iteration:
+		msg = formatTicks(endtime - iteration)
+	case endtime != math.MaxUint64:
+		msg = formatTicks(0)
+	}
+
+	sw, _ := screen.Size()
+	b := text.BoundString(face, msg)
+	x := (sw - b.Dx()) / 2
+	ebitenutil.DrawRect(screen, float64(x-hudPadding), hudPadding, float64(b.Dx()+hudPadding*2), float64(b.Dy()+hudPadding*2), hudPanel)
+	text.Draw(screen, msg, face, x, hudPadding*2-b.Min.Y, hudText)
+}
+
+// drawFeed draws the kill feed (top right).
+// Old lines fade out.
+func (h *HUD) drawFeed(screen *ebiten.Image) {
+	face := resources.Fonts.ToolTipFace
+	iteration, _, _ := h.world.Stats()
+	sw, _ := screen.Size()
+
+	y := hudPadding
+	for _, l := range h.feed {
+		age := int(iteration - l.iteration)
+		if age > feedTime {
+			continue
+		}
+		alpha := 1.0
+		if age > feedTime-feedFade {
+			alpha = float64(feedTime-age) / feedFade
+		}
+
+		b := text.BoundString(face, l.text)
+		x := sw - b.Dx() - hudPadding*2
+		ebitenutil.DrawRect(screen, float64(x-hudPadding), float64(y), float64(b.Dx()+hudPadding*2), hudRow, fade(hudPanel, alpha))
+		ebitenutil.DrawRect(screen, float64(x-hudPadding), float64(y), 4, hudRow, fade(l.clr, alpha))
+		text.Draw(screen, l.text, face, x, y+hudRow-7, fade(hudText, alpha))
+		y += hudRow + 2
+	}
+}
+
+// drawGameOver draws the winner (center) when the game is over.
+func (h *HUD) drawGameOver(screen *ebiten.Image) {
+	if !h.world.GameOver() {
+		return
+	}
+
+	msg := "GAME OVER: no winner"
+	clr := hudText
+	if w := h.world.Winner(); w != nil {
+		clr = w.RGB()
+		if w.Team() != "" {
+			msg = fmt.Sprintf("GAME OVER: team %s wins!", w.Team())
+		} else {
+			msg = fmt.Sprintf("GAME OVER: %s wins!", w.Name())
+		}
+	}
+
+	face := resources.Fonts.BigTitleFace
+	sw, sh := screen.Size()
+	b := text.BoundString(face, msg)
+	x, y := (sw-b.Dx())/2, (sh-b.Dy())/2
+	ebitenutil.DrawRect(screen, float64(x-hudPadding*2), float64(y-hudPadding*2), float64(b.Dx()+hudPadding*4), float64(b.Dy()+hudPadding*4), hudPanel)
+	text.Draw(screen, msg, face, x, y-b.Min.Y, clr)
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+// playerLabel returns the name and the team of a player (e.g. 'Bob [red]').
+func playerLabel(p *core.Ship) string {
+	name := []rune(p.Name())
+	if len(name) > hudNameMax {
+		name = append(name[:hudNameMax-1], '…')
+	}
+	if p.Team() != "" {
+		return fmt.Sprintf("%s [%s]", string(name), p.Team())
+	}
+	return string(name)
+}
+
+// formatTicks returns the ticks as time (e.g. '2:59', see core.TPS).
+func formatTicks(ticks uint64) string {
+	seconds := (ticks + core.TPS - 1) / core.TPS // round up
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// speedColor returns the color of a speed bar (green to red).
+func speedColor(speed float64) color.RGBA {
+	return color.RGBA{R: uint8(80 + 175*speed), G: uint8(220 - 140*speed), B: 80, A: 0xff}
+}
+
+// fade returns the color with the alpha factor (premultiplied alpha).
+func fade(c color.RGBA, alpha float64) color.RGBA {
+	return color.RGBA{
+		R: uint8(float64(c.R) * alpha),
+		G: uint8(float64(c.G) * alpha),
+		B: uint8(float64(c.B) * alpha),
+		A: uint8(float64(c.A) * alpha),
+	}
+}
+
+// maxInt returns the bigger value.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
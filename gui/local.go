@@ -0,0 +1,98 @@
+package gui
+
+import (
+	"SpaceBumper/core"
+	"fmt"
+	"strings"
+)
+
+// LocalPlayer is a human player on this machine (hot-seat).
+// Each local player should use other input devices (see Bindings).
+type LocalPlayer struct {
+	Name     string
+	Color    string
+	Team     string // optional
+	Class    string // optional
+	Controls Bindings
+}
+
+// localColors are the default colors of the local players.
+var localColors = []string{"blue", "red", "green", "orange"}
+
+// DefaultLocalPlayer returns the default of the i-th (0 = first) of n local players.
+// A single player can use all devices, several players share the keyboard and the gamepads:
+//
+//	1st player: wasd, gamepad
+//	2nd player: arrows, gamepad2
+//	3rd player: mouse, gamepad3
+//	4th player: gamepad4 (and so on)
+func DefaultLocalPlayer(i, n int) LocalPlayer {
+	l := LocalPlayer{
+		Name:  fmt.Sprintf("Player %d", i+1),
+		Color: localColors[i%len(localColors)],
+	}
+	switch {
+	case n <= 1:
+		l.Name = "Local Player"
+		l.Controls = DefaultBindings()
+	case i == 0:
+		l.Controls = Bindings{Keys: []KeySet{KeysWASD}, Gamepads: []int{0}}
+	case i == 1:
+		l.Controls = Bindings{Keys: []KeySet{KeysArrows}, Gamepads: []int{1}}
+	case i == 2:
+		l.Controls = Bindings{Mouse: true, Gamepads: []int{2}}
+	default:
+		l.Controls = Bindings{Gamepads: []int{i}}
+	}
+	return l
+}
+
+// ParseLocalPlayers parses the local players from semicolon separated lists (e.g. controls 'wasd;arrows').
+// The number of controls is the number of players, missing values are taken from DefaultLocalPlayer.
+func ParseLocalPlayers(names, colors, teams, classes, controls string) ([]LocalPlayer, error) {
+	ctrl := strings.Split(controls, ";")
+	players := make([]LocalPlayer, len(ctrl))
+	for i := range players {
+		l := DefaultLocalPlayer(i, len(ctrl))
+		if s := nth(names, i); s != "" {
+			l.Name = s
+		}
+		if s := nth(colors, i); s != "" {
+			l.Color = s
+		}
+		l.Team = nth(teams, i)
+		l.Class = nth(classes, i)
+		if s := strings.TrimSpace(ctrl[i]); s != "" {
+			b, err := ParseBindings(s)
+			if err != nil {
+				return nil, fmt.Errorf("local player %d: %v", i+1, err)
+			}
+			l.Controls = b
+		}
+		players[i] = l
+	}
+	return players, nil
+}
+
+// AddLocalPlayers adds the local players to the world.
+// Returns the input devices of the new player ids (see Game.SetLocals).
+func AddLocalPlayers(world *core.WorldMap, players []LocalPlayer) (map[int]Bindings, error) {
+	locals := make(map[int]Bindings, len(players))
+	for _, l := range players {
+		id, err := world.AddPlayer(l.Name, l.Color, l.Team, l.Class, nil)
+		if err != nil {
+			return nil, err
+		}
+		locals[id] = l.Controls
+	}
+	return locals, nil
+}
+
+// nth returns the i-th value of a semicolon separated list (or an empty string).
+func nth(list string, i int) string {
+	values := strings.Split(list, ";")
+	if i >= len(values) {
+		return ""
+	}
+	return strings.TrimSpace(values[i])
+}
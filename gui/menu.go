@@ -0,0 +1,418 @@
+package gui
+
+import (
+	"SpaceBumper/bot"
+	"SpaceBumper/core"
+	"SpaceBumper/gui/resources"
+	"SpaceBumper/remote"
+	"errors"
+	"fmt"
+	"github.com/blizzy78/ebitenui"
+	"github.com/blizzy78/ebitenui/widget"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"image"
+	"strconv"
+	"strings"
+)
+
+// menu layout
+const (
+	menuWidth  = 960 // window width (px)
+	menuHeight = 720 // window height (px)
+)
+
+// botColors are the colors of the built-in bots.
+var botColors = []string{"#e0c000", "#a040e0", "#00c0c0", "#e060a0", "#80a0ff", "#a0e040", "#ff8060", "#c0c0c0"}
+
+// errQuit ends the menu (see Menu.Update).
+var errQuit = errors.New("quit")
+
+// MatchSetup configures the matches started from the menu (see RunMenu).
+// The values are the defaults of the menu inputs.
+type MatchSetup struct {
+	Map     string  // map name (see core.LoadWorldMap)
+	Endtime uint64  // max. ticks, the map header overrides this value
+	Speed   float64 // game speed factor (see core.Clock.SetSpeed)
+	Players int     // players the remote server waits for (local player and bots included)
+	Bots    int     // built-in bots (see bot.StarBot)
+
+	Remote bool   // start the remote server
+	Addr   string // server ip
+	Port   string // server port
+
+	Locals []LocalPlayer // local players (the menu can change the number, see DefaultLocalPlayer)
+
+	// Configure is called for each new world before the players are added (optional).
+	// Use it to apply rules that are not part of the menu (game mode, tie-breaker, ...).
+	Configure func(world *core.WorldMap) error
+}
+
+// interface check: ebiten.Game
+var _ ebiten.Game = (*Menu)(nil)
+
+// Menu is the start screen. It starts and restarts matches and opens the map editor.
+// Escape returns from the match (paused) or the map editor to the menu.
+// (see RunMenu)
+type Menu struct {
+	setup  MatchSetup
+	ui     *ebitenui.UI
+	scene  ebiten.Game    // shown match or map editor (nil = menu)
+	game   *Game          // last match (see Resume)
+	server *remote.Server // started with the first remote match
+	quit   bool           // see errQuit
+
+	// widgets
+	mapList      *widget.List
+	endtimeInput *widget.TextInput
+	playersInput *widget.TextInput
+	botsInput    *widget.TextInput
+	remoteCheck  *widget.Checkbox
+	localsInput  *widget.TextInput
+	resumeButton *widget.Button
+	statusLabel  *widget.Label
+}
+
+// RunMenu starts a GUI window with the start screen.
+//
+// This call is blocking.
+func RunMenu(setup MatchSetup) error {
+	m := NewMenu(setup)
+
+	// config window
+	ebiten.SetWindowTitle("Space Bumper")
+	ebiten.SetWindowIcon([]image.Image{resources.Games.Logo})
+	ebiten.SetWindowSize(menuWidth, menuHeight)
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	ebiten.SetTPS(GameSpeed)
+
+	// run (BLOCKING)
+	if err := ebiten.RunGame(m); err != errQuit {
+		return err
+	}
+	return nil
+}
+
+// NewMenu creates the start screen with the given defaults.
+func NewMenu(setup MatchSetup) *Menu {
+	m := &Menu{setup: setup}
+	m.ui = &ebitenui.UI{Container: m.createUI()}
+	return m
+}
+
+//--------  Update  --------------------------------------------------------------------------------------------------//
+
+// Layout returns the size of the shown scene (see ebiten.Game).
+func (m *Menu) Layout(outsideWidth, outsideHeight int) (int, int) {
+	if m.scene != nil {
+		return m.scene.Layout(outsideWidth, outsideHeight)
+	}
+	return menuWidth, menuHeight
+}
+
+// Update updates the shown scene (see ebiten.Game).
+func (m *Menu) Update() error {
+	if m.scene != nil {
+		if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			m.show(nil)
+			return nil
+		}
+		return m.scene.Update()
+	}
+
+	if m.quit {
+		return errQuit
+	}
+	m.resumeButton.GetWidget().Disabled = m.game == nil
+	m.ui.Update()
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		m.resume()
+	}
+	return nil
+}
+
+// Draw draws the shown scene (see ebiten.Game).
+func (m *Menu) Draw(screen *ebiten.Image) {
+	if m.scene != nil {
+		m.scene.Draw(screen)
+		return
+	}
+
+	// background image
+	op := new(ebiten.DrawImageOptions)
+	op.GeoM.Scale(menuWidth/2600.0, menuHeight/1839.0) // bgImage is 2600px * 1839px
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(resources.Games.Bg, op)
+
+	m.ui.Draw(screen)
+}
+
+// Start starts a new match with the menu settings.
+// A running match is discarded.
+func (m *Menu) Start() error {
+	name, _ := m.mapList.SelectedEntry().(string)
+	if name == "" {
+		return errors.New("select a map")
+	}
+
+	// inputs
+	endtime := uint64(0)
+	if s := strings.TrimSpace(m.endtimeInput.InputText); s != "" {
+		var err error
+		if endtime, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return fmt.Errorf("invalid endtime '%s'", s)
+		}
+	}
+	players, err := strconv.Atoi(strings.TrimSpace(m.playersInput.InputText))
+	if err != nil || players < 1 {
+		return fmt.Errorf("invalid players '%s'", m.playersInput.InputText)
+	}
+	bots, err := strconv.Atoi(strings.TrimSpace(m.botsInput.InputText))
+	if err != nil || bots < 0 {
+		return fmt.Errorf("invalid bots '%s'", m.botsInput.InputText)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(m.localsInput.InputText))
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid local players '%s'", m.localsInput.InputText)
+	}
+
+	// create world
+	world, err := core.LoadWorldMap(name, m.setup.Endtime)
+	if err != nil {
+		return err
+	}
+	if m.setup.Configure != nil {
+		if err := m.setup.Configure(world); err != nil {
+			return err
+		}
+	}
+	if endtime > 0 {
+		world.SetEndtime(endtime)
+	}
+
+	// add local players (first players) and bots
+	locals, err := AddLocalPlayers(world, m.localPlayers(n))
+	if err != nil {
+		return err
+	}
+	for i := 0; i < bots; i++ {
+		id, err := world.AddPlayer(fmt.Sprintf("Bot %d", i+1), botColors[i%len(botColors)], "", "", nil)
+		if err != nil {
+			return err
+		}
+		ship, _ := world.Player(id)
+		ship.SetController(bot.NewStarBot())
+	}
+
+	// remote server
+	if m.remoteCheck.State() == widget.CheckboxChecked {
+		if m.server == nil {
+			ser := remote.NewServer(m.setup.Addr, m.setup.Port, players)
+			if err := ser.Start(); err != nil {
+				return err
+			}
+			m.server = ser
+		}
+		m.server.SetWaitPlayer(players)
+		m.server.SetWorld(world)
+	} else if m.server != nil {
+		_ = m.server.Close()
+		m.server = nil
+	}
+
+	// show match
+	clock := core.NewClock(world, core.TPS)
+	clock.SetSpeed(m.setup.Speed)
+	m.game = NewGame(world, clock)
+	m.game.SetLocals(locals)
+	m.show(m.game)
+	ebiten.SetWindowTitle("Space Bumper - " + world.Info().Name)
+	return nil
+}
+
+// localPlayers returns n local players.
+// The names, colors, teams and classes of the configured players are kept,
+// the input devices only if the number of players is unchanged (see DefaultLocalPlayer).
+func (m *Menu) localPlayers(n int) []LocalPlayer {
+	players := make([]LocalPlayer, n)
+	for i := range players {
+		players[i] = DefaultLocalPlayer(i, n)
+		if i < len(m.setup.Locals) {
+			controls := players[i].Controls
+			players[i] = m.setup.Locals[i]
+			if n != len(m.setup.Locals) {
+				players[i].Controls = controls
+			}
+		}
+	}
+	return players
+}
+
+// show shows the scene (nil = menu) and resizes the window.
+func (m *Menu) show(scene ebiten.Game) {
+	m.scene = scene
+	w, h := m.Layout(0, 0)
+	ebiten.SetWindowSize(w, h)
+	if scene == nil {
+		ebiten.SetWindowTitle("Space Bumper")
+	}
+}
+
+// resume shows the paused game again (if any).
+// The clock is reset, so the pause is not caught up (see core.Clock.Reset).
+func (m *Menu) resume() {
+	if m.game == nil {
+		return
+	}
+	if m.game.clock != nil {
+		m.game.clock.Reset()
+	}
+	m.show(m.game)
+}
+
+//--------  UI  ------------------------------------------------------------------------------------------------------//
+
+// createUI creates the start screen.
+func (m *Menu) createUI() *widget.Container {
+	root := widget.NewContainer(widget.ContainerOpts.Layout(widget.NewAnchorLayout()))
+
+	panel := widget.NewContainer(
+		widget.ContainerOpts.BackgroundImage(resources.Panels.Image),
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+			widget.RowLayoutOpts.Padding(resources.Panels.Padding),
+			widget.RowLayoutOpts.Spacing(12),
+		)),
+		widget.ContainerOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.AnchorLayoutData{
+			HorizontalPosition: widget.AnchorLayoutPositionCenter,
+			VerticalPosition:   widget.AnchorLayoutPositionCenter,
+		})),
+	)
+	root.AddChild(panel)
+
+	// title
+	panel.AddChild(newLabel("Space Bumper", resources.Texts.BigTitleFace))
+
+	// settings
+	form := widget.NewContainer(widget.ContainerOpts.Layout(widget.NewGridLayout(
+		widget.GridLayoutOpts.Columns(2),
+		widget.GridLayoutOpts.Spacing(20, 10),
+		widget.GridLayoutOpts.Stretch([]bool{false, true}, nil),
+	)))
+	panel.AddChild(form)
+
+	// map
+	maps := core.MapNames()
+	entries := make([]interface{}, 0, len(maps))
+	var selected interface{}
+	for _, n := range maps {
+		entries = append(entries, n)
+		if strings.EqualFold(n, strings.TrimSuffix(m.setup.Map, ".txt")) {
+			selected = n
+		}
+	}
+	form.AddChild(newLabel("Map", resources.Texts.Face))
+	m.mapList = newList(entries, 180)
+	if selected != nil {
+		m.mapList.SetSelectedEntry(selected)
+	}
+	form.AddChild(m.mapList)
+
+	// numbers
+	form.AddChild(newLabel("Endtime (ticks)", resources.Texts.Face))
+	m.endtimeInput = newTextInput("map default")
+	form.AddChild(m.endtimeInput)
+	form.AddChild(newLabel("Players (remote)", resources.Texts.Face))
+	m.playersInput = newTextInput("players")
+	m.playersInput.InputText = strconv.Itoa(m.setup.Players)
+	form.AddChild(m.playersInput)
+	form.AddChild(newLabel("Bots", resources.Texts.Face))
+	m.botsInput = newTextInput("bots")
+	m.botsInput.InputText = strconv.Itoa(m.setup.Bots)
+	form.AddChild(m.botsInput)
+
+	// toggles
+	form.AddChild(newLabel("Remote server", resources.Texts.Face))
+	remoteCheck := newCheckbox(m.setup.Addr+":"+m.setup.Port, m.setup.Remote)
+	m.remoteCheck = remoteCheck.Checkbox()
+	form.AddChild(remoteCheck)
+	form.AddChild(newLabel("Local players", resources.Texts.Face))
+	m.localsInput = newTextInput("0 = none")
+	m.localsInput.InputText = strconv.Itoa(len(m.setup.Locals))
+	form.AddChild(m.localsInput)
+
+	// buttons
+	buttons := newRow()
+	buttons.AddChild(newButton("Start", func() {
+		if err := m.Start(); err != nil {
+			m.statusLabel.Label = wrap("err: "+err.Error(), 60)
+		} else {
+			m.statusLabel.Label = "Escape: back to the menu"
+		}
+	}))
+	m.resumeButton = newButton("Resume", m.resume)
+	buttons.AddChild(m.resumeButton)
+	buttons.AddChild(newButton("Map Editor", func() {
+		e := NewEditor()
+		name, _ := m.mapList.SelectedEntry().(string)
+		e.nameInput.InputText = name
+		if name != "" {
+			if err := e.Load(name); err != nil {
+				e.status("err: %v", err)
+			}
+		}
+		m.show(e)
+	}))
+	buttons.AddChild(newButton("Quit", func() {
+		m.quit = true
+	}))
+	panel.AddChild(buttons)
+
+	// status
+	m.statusLabel = newLabel("", resources.Texts.SmallFace)
+	panel.AddChild(m.statusLabel)
+
+	return root
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+// newList creates a list of strings with a max. height.
+func newList(entries []interface{}, maxHeight int) *widget.List {
+	return widget.NewList(
+		widget.ListOpts.ContainerOpts(widget.ContainerOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.GridLayoutData{
+			MaxHeight: maxHeight,
+		}))),
+		widget.ListOpts.ScrollContainerOpts(widget.ScrollContainerOpts.Image(resources.Lists.Image)),
+		widget.ListOpts.SliderOpts(
+			widget.SliderOpts.Images(resources.Lists.Track, resources.Lists.Handle),
+			widget.SliderOpts.HandleSize(resources.Lists.HandleSize),
+			widget.SliderOpts.TrackPadding(resources.Lists.TrackPadding),
+		),
+		widget.ListOpts.HideHorizontalSlider(),
+		widget.ListOpts.Entries(entries),
+		widget.ListOpts.EntryLabelFunc(func(e interface{}) string {
+			return e.(string)
+		}),
+		widget.ListOpts.EntryFontFace(resources.Lists.Face),
+		widget.ListOpts.EntryColor(resources.Lists.Entry),
+		widget.ListOpts.EntryTextPadding(resources.Lists.EntryPadding),
+	)
+}
+
+// newCheckbox creates a labeled checkbox.
+func newCheckbox(text string, checked bool) *widget.LabeledCheckbox {
+	c := widget.NewLabeledCheckbox(
+		widget.LabeledCheckboxOpts.CheckboxOpts(
+			widget.CheckboxOpts.ButtonOpts(widget.ButtonOpts.Image(resources.Checkboxes.Image)),
+			widget.CheckboxOpts.Image(resources.Checkboxes.Graphic),
+		),
+		widget.LabeledCheckboxOpts.LabelOpts(widget.LabelOpts.Text(text, resources.Labels.Face, resources.Labels.Text)),
+		widget.LabeledCheckboxOpts.Spacing(resources.Checkboxes.Spacing),
+	)
+	c.GetWidget() // init (see Checkbox)
+	if checked {
+		c.Checkbox().SetState(widget.CheckboxChecked)
+	}
+	return c
+}
@@ -1,12 +1,9 @@
 package resources
 
 import (
-	"embed"
+	"SpaceBumper/gui/resources/sprites"
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	_ "image/jpeg" // needed for ebitenutil.NewImageFromReader()
-	_ "image/png"  // needed for ebitenutil.NewImageFromReader()
-	"log"
+	"image"
 )
 
 var Games *GameResources
@@ -26,41 +23,54 @@ type GameResources struct {
 	Star   *ebiten.Image
 	Anti   *ebiten.Image
 	Tile   *ebiten.Image
+
+	Shield   *ebiten.Image
+	Mass     *ebiten.Image
+	Teleport *ebiten.Image
+	Conveyor *ebiten.Image // points right
+	Crumble  *ebiten.Image
+	Ring     *ebiten.Image // white, used for tinted markers
+	Ship     *ebiten.Image // ship without paint (see Paint)
+	Paint    *ebiten.Image // white ship paint, tinted with the ship color
+
+	Hill *ebiten.Image
+	Flag *ebiten.Image
+	Goal *ebiten.Image
 }
 
 func init() {
 	Games = &GameResources{
-		Bg:     loadGameImg("game/bg.jpg"),
-		Block:  loadGameImg("game/block.png"),
-		Blue:   loadGameImg("game/blue.png"),
-		Boost:  loadGameImg("game/boost.png"),
-		Error:  loadGameImg("game/error.png"),
-		Green:  loadGameImg("game/green.png"),
-		Logo:   loadGameImg("game/logo.png"),
-		Orange: loadGameImg("game/orange.png"),
-		Red:    loadGameImg("game/red.png"),
-		Slow:   loadGameImg("game/slow.png"),
-		Spawn:  loadGameImg("game/spawn.png"),
-		Star:   loadGameImg("game/star.png"),
-		Anti:   loadGameImg("game/anti.png"),
-		Tile:   loadGameImg("game/tile.png"),
-	}
-}
+		Bg:     loadGameImg(sprites.Games.Bg),
+		Block:  loadGameImg(sprites.Games.Block),
+		Blue:   loadGameImg(sprites.Games.Blue),
+		Boost:  loadGameImg(sprites.Games.Boost),
+		Error:  loadGameImg(sprites.Games.Error),
+		Green:  loadGameImg(sprites.Games.Green),
+		Logo:   loadGameImg(sprites.Games.Logo),
+		Orange: loadGameImg(sprites.Games.Orange),
+		Red:    loadGameImg(sprites.Games.Red),
+		Slow:   loadGameImg(sprites.Games.Slow),
+		Spawn:  loadGameImg(sprites.Games.Spawn),
+		Star:   loadGameImg(sprites.Games.Star),
+		Anti:   loadGameImg(sprites.Games.Anti),
+		Tile:   loadGameImg(sprites.Games.Tile),
 
-//go:embed game
-var gFS embed.FS
+		Shield:   loadGameImg(sprites.Games.Shield),
+		Mass:     loadGameImg(sprites.Games.Mass),
+		Teleport: loadGameImg(sprites.Games.Teleport),
+		Conveyor: loadGameImg(sprites.Games.Conveyor),
+		Crumble:  loadGameImg(sprites.Games.Crumble),
+		Ring:     loadGameImg(sprites.Games.Ring),
+		Ship:     loadGameImg(sprites.Games.Ship),
+		Paint:    loadGameImg(sprites.Games.Paint),
 
-func loadGameImg(name string) *ebiten.Image {
-	// open reader
-	r, err := gFS.Open(name)
-	if err != nil {
-		log.Fatalf("err: loadGameImg: %v\n", err)
+		Hill: loadGameImg(sprites.Games.Hill),
+		Flag: loadGameImg(sprites.Games.Flag),
+		Goal: loadGameImg(sprites.Games.Goal),
 	}
-	// get image
-	eim, _, err := ebitenutil.NewImageFromReader(r)
-	if err != nil {
-		log.Fatalf("err: loadGameImg: %v\n", err)
-	}
-	// return
-	return eim
+}
+
+// loadGameImg converts an embedded sprite (see sprites.Games) to an ebiten image.
+func loadGameImg(img image.Image) *ebiten.Image {
+	return ebiten.NewImageFromImage(img)
 }
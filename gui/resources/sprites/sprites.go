@@ -0,0 +1,95 @@
+// Package sprites embeds the game images (cells, ships and background) as image.Image.
+// It does not depend on ebiten, the headless renderer uses it without a display (see render).
+// The GUI converts the images to ebiten images (see resources.Games).
+package sprites
+
+import (
+	"embed"
+	"image"
+	_ "image/jpeg" // needed for image.Decode()
+	_ "image/png"  // needed for image.Decode()
+	"log"
+)
+
+var Games *GameImages
+
+type GameImages struct {
+	Bg     image.Image
+	Block  image.Image
+	Blue   image.Image
+	Boost  image.Image
+	Error  image.Image
+	Green  image.Image
+	Logo   image.Image
+	Orange image.Image
+	Red    image.Image
+	Slow   image.Image
+	Spawn  image.Image
+	Star   image.Image
+	Anti   image.Image
+	Tile   image.Image
+
+	Shield   image.Image
+	Mass     image.Image
+	Teleport image.Image
+	Conveyor image.Image // points right
+	Crumble  image.Image
+	Ring     image.Image // white, used for tinted markers
+	Ship     image.Image // ship without paint (see Paint)
+	Paint    image.Image // white ship paint, tinted with the ship color
+
+	Hill image.Image
+	Flag image.Image
+	Goal image.Image
+}
+
+func init() {
+	Games = &GameImages{
+		Bg:     loadGameImg("game/bg.jpg"),
+		Block:  loadGameImg("game/block.png"),
+		Blue:   loadGameImg("game/blue.png"),
+		Boost:  loadGameImg("game/boost.png"),
+		Error:  loadGameImg("game/error.png"),
+		Green:  loadGameImg("game/green.png"),
+		Logo:   loadGameImg("game/logo.png"),
+		Orange: loadGameImg("game/orange.png"),
+		Red:    loadGameImg("game/red.png"),
+		Slow:   loadGameImg("game/slow.png"),
+		Spawn:  loadGameImg("game/spawn.png"),
+		Star:   loadGameImg("game/star.png"),
+		Anti:   loadGameImg("game/anti.png"),
+		Tile:   loadGameImg("game/tile.png"),
+
+		Shield:   loadGameImg("game/shield.png"),
+		Mass:     loadGameImg("game/mass.png"),
+		Teleport: loadGameImg("game/teleport.png"),
+		Conveyor: loadGameImg("game/conveyor.png"),
+		Crumble:  loadGameImg("game/crumble.png"),
+		Ring:     loadGameImg("game/ring.png"),
+		Ship:     loadGameImg("game/ship.png"),
+		Paint:    loadGameImg("game/paint.png"),
+
+		Hill: loadGameImg("game/hill.png"),
+		Flag: loadGameImg("game/flag.png"),
+		Goal: loadGameImg("game/goal.png"),
+	}
+}
+
+//go:embed game
+var gFS embed.FS
+
+func loadGameImg(name string) image.Image {
+	// open reader
+	r, err := gFS.Open(name)
+	if err != nil {
+		log.Fatalf("err: loadGameImg: %v\n", err)
+	}
+	defer r.Close()
+	// decode image
+	img, _, err := image.Decode(r)
+	if err != nil {
+		log.Fatalf("err: loadGameImg: %v\n", err)
+	}
+	// return
+	return img
+}
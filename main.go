@@ -1,26 +1,43 @@
 package main
 
 import (
+	"SpaceBumper/bot"
 	"SpaceBumper/core"
 	"SpaceBumper/gui"
+	"SpaceBumper/mapgen"
+	"SpaceBumper/maplint"
 	"SpaceBumper/myai/ai"
 	"SpaceBumper/remote"
+	"SpaceBumper/render"
+	"errors"
 	"flag"
+	"fmt"
+	"math"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 )
 
 const VERSION = "1.1"
 
+// maxOvertime ticks a headless recording runs after the end time (tie-breaker) until it is stopped
+const maxOvertime = 60 * core.TPS
+
 func main() {
 
 	// AI
 	aiMode := flag.Bool("ai", false, "start program as ai client")
 
-	// world settings
+	// world settings (explicit flags override the map header)
 	mapName := flag.String("map", "map1", "the name of the player map")
 	endtime := flag.Uint64("endtime", 10800, "maximum ticks until the game ends")
+	speed := flag.Float64("speed", 1.0, "game speed factor (e.g. 0.5, 2 or 10)")
+	elimination := flag.Bool("elimination", false, "dead ships are removed and the last ship standing wins")
+	mode := flag.String("mode", "", "game mode: koth or ctf (default from the map header)")
+	tieBreak := flag.String("tiebreak", "none", "tie-breaker at the end time: none, overtime, sudden-death or stars")
+	friendlyFire := flag.Bool("friendly-fire", true, "teammates get points for bumping each other")
 
 	// remote server settings
 	remotePly := flag.Bool("remote", false, "starts the server for remote play")
@@ -30,11 +47,45 @@ func main() {
 
 	// local player settings
 	noLocalPly := flag.Bool("no-local", false, "disable local game with mouse; local game needs headless=false")
-	localName := flag.String("name", "Local Player", "your local player name; needs local=true")
-	localColor := flag.String("color", "blue", "your local player color; needs local=true")
+	localName := flag.String("name", "Local Player", "your local player name (semicolon separated for several local players); needs local=true")
+	localColor := flag.String("color", "blue", "your local player color (red, blue, green, orange or #rrggbb; semicolon separated); needs local=true")
+	localTeam := flag.String("team", "", "your local player team (optional; semicolon separated); needs local=true")
+	bots := flag.Int("bots", 0, "number of built-in bots (drive to the nearest star)")
+	localClass := flag.String("class", "", "your local ship class: normal, heavy or light (optional; semicolon separated); needs local=true")
+	controls := flag.String("controls", "all", "your local input devices: all, mouse, wasd, arrows, gamepad, gamepad2 or keys:up/down/left/right/dash/brake (comma separated); "+
+		"several local players are separated by semicolons (e.g. 'wasd;arrows'); needs local=true")
 
 	// gui settings
 	headless := flag.Bool("headless", false, "enable or disable GUI")
+	menu := flag.Bool("menu", false, "open the start screen to set up and restart matches (the flags are the defaults)")
+
+	// recording (headless rendering, e.g. on CI machines without display)
+	record := flag.String("record", "", "record the game to a replay file (see replay)")
+	renderOut := flag.String("render-out", "", "render the game (or the replay) without window: file.png (last frame), file.gif (animation) or a directory (PNG per frame)")
+	replay := flag.String("replay", "", "render the replay file to render-out and exit")
+	rec := render.Options{}
+	flag.Float64Var(&rec.Scale, "render-scale", 0.5, "rendered image scale (1 = 40 pixels per cell); needs render-out")
+	flag.Uint64Var(&rec.Every, "render-every", 6, "render every n-th tick (6 = 10 frames per second); needs render-out")
+	flag.Uint64Var(&rec.From, "render-from", 0, "first rendered tick; needs render-out")
+	flag.Uint64Var(&rec.To, "render-to", 0, "last rendered tick (0 = until the game is over); needs render-out")
+
+	// map tools
+	lint := flag.Bool("lint", false, "check the map (and all map names given as args) and exit; exit code 1 on errors")
+	edit := flag.Bool("edit", false, "open the map in the map editor (new map if it does not exist)")
+	generate := flag.Bool("generate", false, "generate random maps (see gen-*) and exit")
+	gen := mapgen.DefaultOptions()
+	flag.IntVar(&gen.Width, "gen-width", gen.Width, "generated map width (cells); needs generate=true")
+	flag.IntVar(&gen.Height, "gen-height", gen.Height, "generated map height (cells); needs generate=true")
+	flag.StringVar(&gen.Symmetry, "gen-symmetry", gen.Symmetry, "generated map symmetry: "+strings.Join(mapgen.Symmetries, ", ")+"; needs generate=true")
+	flag.Float64Var(&gen.Blocks, "gen-blocks", gen.Blocks, "generated map share of blocks (0.0 - 0.3); needs generate=true")
+	flag.IntVar(&gen.Strips, "gen-strips", gen.Strips, "generated map boost and slow strips; needs generate=true")
+	flag.IntVar(&gen.Holes, "gen-holes", gen.Holes, "generated map void holes; needs generate=true")
+	flag.IntVar(&gen.Stars, "gen-stars", gen.Stars, "generated map stars; needs generate=true")
+	flag.IntVar(&gen.Anti, "gen-anti", gen.Anti, "generated map anti-stars; needs generate=true")
+	flag.IntVar(&gen.Spawns, "gen-spawns", gen.Spawns, "generated map spawns; needs generate=true")
+	flag.Int64Var(&gen.Seed, "gen-seed", 0, "random seed of the first map (0 = current time); needs generate=true")
+	genCount := flag.Int("gen-count", 1, "number of generated maps (seed, seed+1, ...); needs generate=true")
+	genOut := flag.String("gen-out", "", "output file (or directory if gen-count > 1, default: stdout); needs generate=true")
 
 	// parse flags
 	flag.Parse()
@@ -55,38 +106,244 @@ func main() {
 		os.Exit(0)
 	}
 
+	// flags set on the command line
+	isSet := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { isSet[f.Name] = true })
+
+	// lint maps
+	if *lint {
+		players := 0
+		if isSet["player"] {
+			players, _ = strconv.Atoi(*player)
+		}
+		errs := 0
+		for _, name := range append([]string{*mapName}, flag.Args()...) {
+			r := maplint.LintFile(name, players)
+			r.Print(os.Stdout)
+			errs += r.Errors()
+		}
+		if errs > 0 {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// map editor
+	if *edit {
+		if err := gui.RunEditor(*mapName); err != nil {
+			panic(err)
+		}
+		os.Exit(0)
+	}
+
+	// generate maps
+	if *generate {
+		if gen.Seed == 0 {
+			gen.Seed = time.Now().UnixNano()
+		}
+		if err := generateMaps(gen, *genCount, *genOut); err != nil {
+			println("err:", err.Error())
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// render replay
+	if *replay != "" {
+		if err := render.RenderReplay(*replay, *renderOut, rec); err != nil {
+			println("err:", err.Error())
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// rule flags set on the command line (see map header)
+	configure := func(world *core.WorldMap) error {
+		if isSet["endtime"] {
+			world.SetEndtime(*endtime)
+		}
+		if isSet["elimination"] {
+			world.SetElimination(*elimination)
+		}
+		if isSet["friendly-fire"] {
+			world.SetFriendlyFire(*friendlyFire)
+		}
+		if isSet["tiebreak"] {
+			tb, err := core.ParseTieBreak(*tieBreak)
+			if err != nil {
+				return err
+			}
+			world.SetTieBreak(tb)
+		}
+		if *mode != "" {
+			gm, err := core.NewGameMode(*mode)
+			if err != nil {
+				return err
+			}
+			world.SetGameMode(gm)
+		}
+		return nil
+	}
+
+	// local players (hot-seat)
+	name := *localName
+	if !isSet["name"] && strings.Contains(*controls, ";") {
+		name = "" // numbered players (see gui.DefaultLocalPlayer)
+	}
+	localPlayers, err := gui.ParseLocalPlayers(name, *localColor, *localTeam, *localClass, *controls)
+	if err != nil {
+		panic(err)
+	}
+	if *noLocalPly {
+		localPlayers = nil
+	}
+
+	// start screen
+	if *menu {
+		players, err := strconv.Atoi(*player)
+		if err != nil {
+			panic(err)
+		}
+		err = gui.RunMenu(gui.MatchSetup{
+			Map:       *mapName,
+			Endtime:   *endtime,
+			Speed:     *speed,
+			Players:   players,
+			Bots:      *bots,
+			Remote:    *remotePly,
+			Addr:      *srvAddr,
+			Port:      *srvPort,
+			Locals:    localPlayers,
+			Configure: configure,
+		})
+		if err != nil {
+			panic(err)
+		}
+		os.Exit(0)
+	}
+
 	// create world
 	world, err := core.LoadWorldMap(*mapName, *endtime)
 	if err != nil {
 		panic(err)
 	}
 
-	// start server
+	if err := configure(world); err != nil {
+		panic(err)
+	}
+
+	// add local players
+	locals, err := gui.AddLocalPlayers(world, localPlayers)
+	if err != nil {
+		panic(err)
+	}
+
+	// add built-in bots
+	for i := 0; i < *bots; i++ {
+		id, err := world.AddPlayer(fmt.Sprintf("Bot %d", i+1), "#e0c000", "", "", nil)
+		if err != nil {
+			panic(err)
+		}
+		ship, _ := world.Player(id)
+		ship.SetController(bot.NewStarBot())
+	}
+
+	// start server (after the local players and bots, they count for the frozen start)
 	if *remotePly {
 		waitPlayer, err := strconv.Atoi(*player)
 		if err != nil {
 			panic(err)
 		}
-		go remote.RunServer(*srvAddr, *srvPort, world, waitPlayer)
+		ser := remote.NewServer(*srvAddr, *srvPort, waitPlayer)
+		ser.SetWorld(world) // freeze before the clock starts
+		if err := ser.Start(); err != nil {
+			panic(err)
+		}
 	}
 
-	// add local player
-	if !*noLocalPly {
-		_, err = world.AddPlayer(*localName, *localColor, nil)
+	// record the game (stopped at game over, Close waits for the files)
+	var recorder *render.Recorder
+	if *record != "" || *renderOut != "" {
+		recorder, err = render.NewRecorder(*record, *renderOut, rec)
 		if err != nil {
 			panic(err)
 		}
+		recorder.Attach(world)
 	}
 
+	// simulation clock (fixed timestep)
+	clock := core.NewClock(world, core.TPS)
+	clock.SetSpeed(*speed)
+
 	// run GUI (blocking)
-	if *headless {
-		for {
-			world.Update()
-			time.Sleep(16 * time.Millisecond) // ~ 60 tick/sec
+	switch {
+	case *headless && recorder != nil:
+		// run until the game is over (see render.Recorder.Attach)
+		// or the hard stop (last rendered tick or end time with one minute of overtime)
+		limit := rec.To
+		if _, endtime, _ := world.Stats(); limit == 0 && endtime < math.MaxUint64-maxOvertime {
+			limit = endtime + maxOvertime
+		}
+		clock.RunUntil(func() bool {
+			iteration, _, _ := world.Stats()
+			return world.GameOver() || (limit > 0 && iteration >= limit)
+		})
+		if !world.GameOver() {
+			fmt.Printf("WARNING: recording stopped at tick %d before the game was over\n", limit)
+		}
+		if err := recorder.Close(); err != nil {
+			println("err:", err.Error())
+			os.Exit(1)
 		}
-	} else {
-		if err := gui.RunGame("Space Bumper", world, true); err != nil {
+	case *headless:
+		clock.Run()
+	default:
+		if err := gui.RunGame("Space Bumper - "+world.Info().Name, world, clock, locals); err != nil {
 			panic(err)
 		}
+		if recorder != nil {
+			if err := recorder.Close(); err != nil {
+				println("err:", err.Error())
+				os.Exit(1)
+			}
+		}
+	}
+}
+
+// generateMaps generates count maps with the seeds o.Seed, o.Seed+1, ...
+// A single map is written to the file out (or stdout), multiple maps
+// are written to the directory out as 'gen_{seed}.txt'.
+func generateMaps(o mapgen.Options, count int, out string) error {
+	if count > 1 && out != "" {
+		if err := os.MkdirAll(out, 0755); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < count; i++ {
+		text, err := mapgen.Generate(o)
+		if errors.Is(err, mapgen.ErrUnfair) {
+			fmt.Fprintf(os.Stderr, "WARNING: seed %d: %v\n", o.Seed, err) // keep the map
+		} else if err != nil {
+			return err
+		}
+
+		switch {
+		case out == "":
+			fmt.Println(text)
+			fmt.Println()
+		case count > 1:
+			path := filepath.Join(out, fmt.Sprintf("gen_%d.txt", o.Seed))
+			if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+				return err
+			}
+			fmt.Println("generated", path)
+		default:
+			if err := os.WriteFile(out, []byte(text), 0644); err != nil {
+				return err
+			}
+			fmt.Println("generated", out)
+		}
+		o.Seed++
 	}
+	return nil
 }
@@ -0,0 +1,341 @@
+package mapgen
+
+import (
+	"SpaceBumper/core"
+	"SpaceBumper/maplint"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Symmetries all supported symmetries (see Options)
+//
+//	none      no symmetry
+//	mirror    mirrored at the vertical axis (left = right)
+//	mirror-xy mirrored at both axes (four equal quarters)
+//	rotate    rotated by 180° around the center
+var Symmetries = []string{"none", "mirror", "mirror-xy", "rotate"}
+
+// Options configures the map generator (see DefaultOptions).
+// The number of spawns, stars and anti-stars is rounded up to a multiple of the symmetry.
+type Options struct {
+	Width    int     // grid width (cells)
+	Height   int     // grid height (cells)
+	Symmetry string  // see Symmetries
+	Blocks   float64 // share of block cells (0.0 - 0.3)
+	Strips   int     // number of boost and slow strips
+	Holes    int     // number of void holes
+	Stars    int     // number of stars
+	Anti     int     // number of anti-stars
+	Spawns   int     // number of spawns
+	Seed     int64   // random seed (same seed and options = same map)
+}
+
+// DefaultOptions returns the options of a medium-sized map for four players.
+func DefaultOptions() Options {
+	return Options{
+		Width:    32,
+		Height:   18,
+		Symmetry: "mirror-xy",
+		Blocks:   0.06,
+		Strips:   4,
+		Holes:    4,
+		Stars:    8,
+		Anti:     4,
+		Spawns:   4,
+		Seed:     1,
+	}
+}
+
+// maxAttempts is the number of maps that are generated until one passes the linter.
+// Maps without symmetry need the most attempts (random spawns are rarely fair).
+const maxAttempts = 1000
+
+// ErrUnfair is returned with a playable map that has unfair spawns (see Generate).
+var ErrUnfair = errors.New("unfair spawns")
+
+// Generate returns a playable map in the map file format (with header, see core.NewWorldMap).
+// Each generated map is checked with the linter (see maplint.Lint).
+// Maps with errors, unfair spawns or fewer spawns, stars or anti-stars than requested are discarded and generated again.
+//
+// If no fair map is found, the map without errors with the fewest unfair spawn warnings
+// is returned with an error that wraps ErrUnfair (the caller decides to use it or not).
+func Generate(o Options) (string, error) {
+	if err := o.validate(); err != nil {
+		return "", err
+	}
+
+	rnd := rand.New(rand.NewSource(o.Seed))
+	best, bestUnfair := "", 0
+	for i := 0; i < maxAttempts; i++ {
+		g := newGrid(o, rnd)
+		if !g.generate() {
+			continue
+		}
+		text := g.String()
+
+		r := maplint.Lint("generated", []byte(text), o.Spawns)
+		if r.Errors() > 0 {
+			continue
+		}
+		n := unfair(r)
+		if n == 0 {
+			return text, nil
+		}
+		if best == "" || n < bestUnfair {
+			best, bestUnfair = text, n
+		}
+	}
+	if best != "" {
+		return best, fmt.Errorf("%w: no fair map found after %d attempts (%d warnings)", ErrUnfair, maxAttempts, bestUnfair)
+	}
+	return "", fmt.Errorf("no playable map found after %d attempts: try other options", maxAttempts)
+}
+
+//--------  Grid  ----------------------------------------------------------------------------------------------------//
+
+// grid is a map under construction.
+type grid struct {
+	o     Options
+	rnd   *rand.Rand
+	cells [][]byte // [xCol][yRow]
+}
+
+// newGrid returns a grid with a void border and ground inside.
+func newGrid(o Options, rnd *rand.Rand) *grid {
+	g := &grid{o: o, rnd: rnd, cells: make([][]byte, o.Width)}
+	for x := range g.cells {
+		g.cells[x] = make([]byte, o.Height)
+		for y := range g.cells[x] {
+			if x == 0 || y == 0 || x == o.Width-1 || y == o.Height-1 {
+				g.cells[x][y] = core.None
+			} else {
+				g.cells[x][y] = core.Tile
+			}
+		}
+	}
+	return g
+}
+
+// generate places all features.
+// The order matters: spawns are placed last on free ground away from the void.
+// Returns false if there is no room for all stars, anti-stars and spawns
+// (the share of blocks is approximate).
+func (g *grid) generate() bool {
+	o := g.o
+
+	// void holes (1x1 to 3x3)
+	for i := 0; i < o.Holes; i++ {
+		w, h := 1+g.rnd.Intn(3), 1+g.rnd.Intn(3)
+		x, y := g.randomCell()
+		for dx := 0; dx < w; dx++ {
+			for dy := 0; dy < h; dy++ {
+				g.set(x+dx, y+dy, core.None)
+			}
+		}
+	}
+
+	// boost and slow strips (3 to 6 cells)
+	for i := 0; i < o.Strips; i++ {
+		t := byte(core.Boost)
+		if i%2 == 1 {
+			t = core.Slow
+		}
+		l := 3 + g.rnd.Intn(4)
+		x, y := g.randomCell()
+		horizontal := g.rnd.Intn(2) == 0
+		for j := 0; j < l; j++ {
+			if horizontal {
+				g.setIf(x+j, y, core.Tile, t)
+			} else {
+				g.setIf(x, y+j, core.Tile, t)
+			}
+		}
+	}
+
+	// blocks
+	blocks := int(o.Blocks * float64((o.Width-2)*(o.Height-2)))
+	g.placeRandom(core.Blocked, blocks, false)
+
+	// stars, anti-stars and spawns
+	return g.placeRandom(core.Star, o.Stars, false) >= o.Stars &&
+		g.placeRandom(core.Anti, o.Anti, false) >= o.Anti &&
+		g.placeRandom(core.Spawn, o.Spawns, true) >= o.Spawns
+}
+
+// placeRandom places n cells of the type on free ground (max. 1000 tries).
+// Spawns keep a distance to the void and to other spawns.
+// Returns the number of placed cells (may be less than n, or more because of the symmetry).
+func (g *grid) placeRandom(t byte, n int, safe bool) int {
+	placed := 0
+	for tries := 0; placed < n && tries < 1000; tries++ {
+		x, y := g.randomCell()
+		if safe && !g.isSafe(x, y) {
+			continue
+		}
+		placed += g.setIf(x, y, core.Tile, t)
+	}
+	return placed
+}
+
+// isSafe returns true if the cell and all its symmetric cells are safe spawns
+// (see isSafeCell) and the symmetric cells are at least 3 cells apart.
+func (g *grid) isSafe(x, y int) bool {
+	orbit := g.symmetric(x, y)
+	for i, p := range orbit {
+		if !g.isSafeCell(p[0], p[1]) {
+			return false
+		}
+		for _, q := range orbit[i+1:] {
+			if abs(p[0]-q[0]) <= 2 && abs(p[1]-q[1]) <= 2 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isSafeCell returns true if the cell and all 8 neighbors are ground
+// and there is no spawn within 2 cells.
+func (g *grid) isSafeCell(x, y int) bool {
+	for dx := -2; dx <= 2; dx++ {
+		for dy := -2; dy <= 2; dy++ {
+			t := g.get(x+dx, y+dy)
+			if t == core.Spawn {
+				return false
+			}
+			if dx >= -1 && dx <= 1 && dy >= -1 && dy <= 1 && (t == core.None || t == core.Blocked) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String returns the map in the map file format.
+func (g *grid) String() string {
+	o := g.o
+	sb := new(strings.Builder)
+	sb.WriteString(fmt.Sprintf("name: Generated %d\n", o.Seed))
+	sb.WriteString(fmt.Sprintf("players: %d\n", o.Spawns))
+	sb.WriteString(fmt.Sprintf("// mapgen: size %dx%d, symmetry %s, blocks %.2f, strips %d, holes %d, stars %d, anti %d, spawns %d\n",
+		o.Width, o.Height, o.Symmetry, o.Blocks, o.Strips, o.Holes, o.Stars, o.Anti, o.Spawns))
+	sb.WriteString(core.HeaderEnd + "\n")
+	for y := 0; y < o.Height; y++ {
+		if y > 0 {
+			sb.WriteByte('\n')
+		}
+		for x := 0; x < o.Width; x++ {
+			sb.WriteByte(g.cells[x][y])
+		}
+		sb.WriteByte('|')
+	}
+	return sb.String()
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+// validate checks the options.
+func (o Options) validate() error {
+	if o.Width < 8 || o.Height < 8 || o.Width > 200 || o.Height > 200 {
+		return errors.New("width and height must be between 8 and 200")
+	}
+	valid := false
+	for _, s := range Symmetries {
+		valid = valid || s == o.Symmetry
+	}
+	if !valid {
+		return fmt.Errorf("invalid symmetry '%s': use %s", o.Symmetry, strings.Join(Symmetries, ", "))
+	}
+	if o.Blocks < 0 || o.Blocks > 0.3 {
+		return errors.New("blocks must be between 0.0 and 0.3")
+	}
+	if o.Spawns < 1 || o.Stars < 0 || o.Anti < 0 || o.Strips < 0 || o.Holes < 0 {
+		return errors.New("counts must not be negative and at least one spawn is required")
+	}
+	return nil
+}
+
+// randomCell returns a random cell inside the void border.
+func (g *grid) randomCell() (x, y int) {
+	return 1 + g.rnd.Intn(g.o.Width-2), 1 + g.rnd.Intn(g.o.Height-2)
+}
+
+// get returns the cell type or None outside the grid.
+func (g *grid) get(x, y int) byte {
+	if x < 0 || y < 0 || x >= g.o.Width || y >= g.o.Height {
+		return core.None
+	}
+	return g.cells[x][y]
+}
+
+// set sets the cell and all symmetric cells inside the void border.
+func (g *grid) set(x, y int, t byte) {
+	g.setIf(x, y, 0, t)
+}
+
+// setIf sets the cell and all symmetric cells if they have the type 'if' (0 = any type).
+// Returns the number of changed cells.
+func (g *grid) setIf(x, y int, ifType, t byte) int {
+	n := 0
+	for _, p := range g.symmetric(x, y) {
+		if p[0] < 1 || p[1] < 1 || p[0] >= g.o.Width-1 || p[1] >= g.o.Height-1 {
+			continue // keep the void border
+		}
+		if ifType != 0 && g.cells[p[0]][p[1]] != ifType {
+			continue
+		}
+		g.cells[p[0]][p[1]] = t
+		n++
+	}
+	return n
+}
+
+// symmetric returns the cell and all its symmetric cells (distinct).
+func (g *grid) symmetric(x, y int) [][2]int {
+	mx, my := g.o.Width-1-x, g.o.Height-1-y
+	var all [][2]int
+	switch g.o.Symmetry {
+	case "mirror":
+		all = [][2]int{{x, y}, {mx, y}}
+	case "mirror-xy":
+		all = [][2]int{{x, y}, {mx, y}, {x, my}, {mx, my}}
+	case "rotate":
+		all = [][2]int{{x, y}, {mx, my}}
+	default:
+		all = [][2]int{{x, y}}
+	}
+
+	// distinct (cells on the axis)
+	out := make([][2]int, 0, len(all))
+	for _, p := range all {
+		dup := false
+		for _, q := range out {
+			dup = dup || p == q
+		}
+		if !dup {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// abs returns the absolute value.
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// unfair returns the number of unfair spawn warnings of the linter.
+func unfair(r *maplint.Report) int {
+	n := 0
+	for _, i := range r.Issues {
+		if i.Check == maplint.CheckUnfairSpawns {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,60 @@
+package mapgen
+
+import (
+	"SpaceBumper/core"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGenerateCounts(t *testing.T) {
+	for _, sym := range Symmetries {
+		for seed := int64(1); seed <= 5; seed++ {
+			o := DefaultOptions()
+			o.Symmetry = sym
+			o.Seed = seed
+			o.Spawns = 6
+			o.Stars = 10
+
+			text, err := Generate(o)
+			if err != nil && !errors.Is(err, ErrUnfair) {
+				t.Fatalf("%s seed %d: %v", sym, seed, err)
+			}
+			if !strings.Contains(text, fmt.Sprintf("players: %d\n", o.Spawns)) {
+				t.Errorf("%s seed %d: header without 'players: %d'", sym, seed, o.Spawns)
+			}
+
+			// count the cells of the map (rounded up to the symmetry)
+			_, lines := core.SplitMap([]byte(text))
+			body := strings.Join(lines, "\n")
+			if n := strings.Count(body, string(rune(core.Spawn))); n < o.Spawns {
+				t.Errorf("%s seed %d: %d spawns, want at least %d", sym, seed, n, o.Spawns)
+			}
+			if n := strings.Count(body, string(rune(core.Star))); n < o.Stars {
+				t.Errorf("%s seed %d: %d stars, want at least %d", sym, seed, n, o.Stars)
+			}
+			if n := strings.Count(body, string(rune(core.Anti))); n < o.Anti {
+				t.Errorf("%s seed %d: %d anti-stars, want at least %d", sym, seed, n, o.Anti)
+			}
+		}
+	}
+}
+
+func TestGenerateTooManySpawns(t *testing.T) {
+	o := DefaultOptions()
+	o.Width, o.Height = 8, 8
+	o.Spawns = 20
+	if _, err := Generate(o); err == nil || errors.Is(err, ErrUnfair) {
+		t.Errorf("20 spawns on 8x8: got %v, want an error", err)
+	}
+}
+
+func TestGenerateSeed(t *testing.T) {
+	o := DefaultOptions()
+	a, _ := Generate(o)
+	b, _ := Generate(o)
+	if a != b {
+		t.Error("same seed and options generate different maps")
+	}
+}
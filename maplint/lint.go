@@ -0,0 +1,331 @@
+package maplint
+
+import (
+	"SpaceBumper/core"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Tolerances of the fairness check (see checkFairness)
+const (
+	FairNearest = 3    // max. difference of the distance to the nearest star (cells)
+	FairMean    = 0.25 // max. relative difference of the mean distance to all stars
+)
+
+// Severity of an issue.
+type Severity int
+
+// Severities
+const (
+	Warning Severity = iota // the map works, but should be checked
+	Error                   // the map is broken
+)
+
+// String returns 'warning' or 'error'.
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Check identifies the check that found an issue.
+// Unlike the message, the check ID does not change (e.g. to filter issues, see mapgen).
+type Check string
+
+// Checks
+const (
+	CheckFile         Check = "file"              // the map file cannot be read
+	CheckCharacter    Check = "unknown-character" // unknown cell character
+	CheckMap          Check = "invalid-map"       // invalid header, line widths, ...
+	CheckHeaderKey    Check = "unknown-setting"   // unknown header setting (ignored)
+	CheckSpawnCount   Check = "spawn-count"       // no spawns or fewer spawns than players
+	CheckSpawnReach   Check = "spawn-unreachable" // spawns that cannot reach each other
+	CheckSpawnVoid    Check = "spawn-void"        // spawn next to the void
+	CheckStarReach    Check = "star-unreachable"  // star that cannot be reached from any spawn
+	CheckUnfairSpawns Check = "unfair-spawns"     // different distances from the spawns to the stars
+)
+
+// Issue is a single finding of the linter.
+type Issue struct {
+	Severity Severity
+	Check    Check
+	XCol     int // cell column or -1 for the whole map
+	YRow     int // cell row or -1 for the whole map
+	Message  string
+}
+
+// String returns the issue as text (e.g. "error: 3,5: unknown character 'z'").
+func (i Issue) String() string {
+	if i.XCol < 0 {
+		return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: %d,%d: %s", i.Severity, i.XCol, i.YRow, i.Message)
+}
+
+// Report contains all issues of a map.
+type Report struct {
+	Map    string
+	Issues []Issue
+}
+
+// Errors returns the number of errors.
+func (r *Report) Errors() int {
+	return r.count(Error)
+}
+
+// Warnings returns the number of warnings.
+func (r *Report) Warnings() int {
+	return r.count(Warning)
+}
+
+// Print writes all issues and a summary line.
+func (r *Report) Print(w io.Writer) {
+	for _, i := range r.Issues {
+		_, _ = fmt.Fprintf(w, "%s: %s\n", r.Map, i)
+	}
+	_, _ = fmt.Fprintf(w, "%s: %d error(s), %d warning(s)\n", r.Map, r.Errors(), r.Warnings())
+}
+
+//--------  Lint  ----------------------------------------------------------------------------------------------------//
+
+// LintFile checks the map file '{mapName}.txt' (see core.MapPath).
+// Players is the intended number of players (0 = use the map header).
+func LintFile(mapName string, players int) *Report {
+	path := core.MapPath(mapName)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		r := &Report{Map: path}
+		r.add(Error, CheckFile, -1, -1, "%v", err)
+		return r
+	}
+	return Lint(path, b, players)
+}
+
+// Lint checks a map text.
+// Players is the intended number of players (0 = use the map header).
+//
+// Errors:
+//   - invalid map (header, line widths, ...) and unknown characters
+//   - no spawns or fewer spawns than intended players
+//   - spawns that cannot reach each other
+//   - stars that cannot be reached from any spawn
+//
+// Warnings:
+//   - unknown header settings
+//   - spawns next to the void
+//   - unfair spawns (distance from each spawn to the stars, see FairNearest and FairMean)
+func Lint(name string, b []byte, players int) *Report {
+	r := &Report{Map: name}
+
+	// unknown characters (replaced by the void, like core.NewCell)
+	header, lines := core.SplitMap(b)
+	for yRow, l := range lines {
+		raw := []byte(l)
+		for xCol, c := range raw {
+			if bytes.IndexByte(core.CellTypes, c) < 0 {
+				r.add(Error, CheckCharacter, xCol, yRow, "unknown character '%s'", string(c))
+				raw[xCol] = core.None
+			}
+		}
+		lines[yRow] = string(raw)
+	}
+	text := strings.Join(lines, "\n")
+	if header != "" {
+		text = header + "\n" + core.HeaderEnd + "\n" + text
+	}
+
+	// load map
+	world, err := core.NewWorldMap([]byte(text), 0)
+	if err != nil {
+		r.add(Error, CheckMap, -1, -1, "%v", err)
+		return r
+	}
+
+	// unknown header settings
+	for _, k := range world.Info().Unknown {
+		r.add(Warning, CheckHeaderKey, -1, -1, "unknown header setting '%s' (ignored)", k)
+	}
+
+	// spawn count
+	if world.MaxPlayers() == 0 {
+		r.add(Error, CheckSpawnCount, -1, -1, "no spawns")
+		return r
+	}
+	if players <= 0 {
+		players = world.Info().Players
+	}
+	if players > world.MaxPlayers() {
+		r.add(Error, CheckSpawnCount, -1, -1, "%d spawns for %d players", world.MaxPlayers(), players)
+	}
+
+	// distances from each spawn
+	dist := make([]map[*core.Cell]int, len(world.Spawns()))
+	for i, s := range world.Spawns() {
+		dist[i] = distances(world, s)
+	}
+
+	checkSpawns(r, world, dist)
+	checkStars(r, world, dist)
+	checkFairness(r, world, dist)
+	return r
+}
+
+//--------  Checks  --------------------------------------------------------------------------------------------------//
+
+// checkSpawns reports spawns that cannot reach the other spawns and spawns next to the void.
+func checkSpawns(r *Report, world *core.WorldMap, dist []map[*core.Cell]int) {
+	spawns := world.Spawns()
+
+	// largest group of connected spawns
+	best := 0
+	bestSize := 0
+	for i := range spawns {
+		size := 0
+		for _, s := range spawns {
+			if _, ok := dist[i][s]; ok {
+				size++
+			}
+		}
+		if size > bestSize {
+			best = i
+			bestSize = size
+		}
+	}
+	for _, s := range spawns {
+		if _, ok := dist[best][s]; !ok {
+			r.add(Error, CheckSpawnReach, s.XCol(), s.YRow(), "spawn cannot reach spawn %d,%d", spawns[best].XCol(), spawns[best].YRow())
+		}
+	}
+
+	// next to the void
+	for _, s := range spawns {
+		if nextToVoid(world, s) {
+			r.add(Warning, CheckSpawnVoid, s.XCol(), s.YRow(), "spawn is next to the void")
+		}
+	}
+}
+
+// checkStars reports stars that cannot be reached from any spawn.
+func checkStars(r *Report, world *core.WorldMap, dist []map[*core.Cell]int) {
+	for _, star := range world.Stars() {
+		reachable := false
+		for i := range dist {
+			if _, ok := dist[i][star]; ok {
+				reachable = true
+			}
+		}
+		if !reachable {
+			r.add(Error, CheckStarReach, star.XCol(), star.YRow(), "star cannot be reached from any spawn")
+		}
+	}
+}
+
+// checkFairness compares the distances from each spawn to the stars.
+// The distance to the nearest star and the mean distance to all reachable stars
+// should be similar for all spawns (see FairNearest and FairMean).
+func checkFairness(r *Report, world *core.WorldMap, dist []map[*core.Cell]int) {
+	spawns := world.Spawns()
+	stars := world.Stars()
+	if len(spawns) < 2 || len(stars) == 0 {
+		return
+	}
+
+	nearest := make([]int, 0, len(spawns))
+	mean := make([]float64, 0, len(spawns))
+	for i := range spawns {
+		n, sum, count := -1, 0, 0
+		for _, star := range stars {
+			if d, ok := dist[i][star]; ok {
+				if n < 0 || d < n {
+					n = d
+				}
+				sum += d
+				count++
+			}
+		}
+		if count == 0 {
+			continue // no stars (see checkStars)
+		}
+		nearest = append(nearest, n)
+		mean = append(mean, float64(sum)/float64(count))
+	}
+	if len(nearest) < 2 {
+		return
+	}
+
+	sort.Ints(nearest)
+	sort.Float64s(mean)
+	if nearest[len(nearest)-1]-nearest[0] > FairNearest {
+		r.add(Warning, CheckUnfairSpawns, -1, -1, "unfair spawns: the nearest star is %d to %d cells away", nearest[0], nearest[len(nearest)-1])
+	}
+	if mean[0] > 0 && (mean[len(mean)-1]-mean[0])/mean[0] > FairMean {
+		r.add(Warning, CheckUnfairSpawns, -1, -1, "unfair spawns: the mean distance to the stars is %.1f to %.1f cells", mean[0], mean[len(mean)-1])
+	}
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+// add appends an issue.
+func (r *Report) add(s Severity, c Check, xCol, yRow int, format string, a ...interface{}) {
+	r.Issues = append(r.Issues, Issue{Severity: s, Check: c, XCol: xCol, YRow: yRow, Message: fmt.Sprintf(format, a...)})
+}
+
+// count returns the number of issues with the given severity.
+func (r *Report) count(s Severity) int {
+	n := 0
+	for _, i := range r.Issues {
+		if i.Severity == s {
+			n++
+		}
+	}
+	return n
+}
+
+// nextToVoid returns true if one of the 8 neighbors is the void or outside the grid.
+func nextToVoid(world *core.WorldMap, c *core.Cell) bool {
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			n := world.Cell(c.XCol()+dx, c.YRow()+dy)
+			if (dx != 0 || dy != 0) && (n == nil || n.Type() == core.None) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// distances returns the distance (in cells) from the start cell to all reachable cells.
+// Ships can drive on all cells except blocks and the void. Teleports are used.
+func distances(world *core.WorldMap, start *core.Cell) map[*core.Cell]int {
+	dist := map[*core.Cell]int{start: 0}
+	queue := []*core.Cell{start}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+
+		next := []*core.Cell{
+			world.Cell(c.XCol()+1, c.YRow()),
+			world.Cell(c.XCol()-1, c.YRow()),
+			world.Cell(c.XCol(), c.YRow()+1),
+			world.Cell(c.XCol(), c.YRow()-1),
+		}
+		if c.Type() == core.Teleport {
+			next = append(next, world.Teleport(c))
+		}
+		for _, n := range next {
+			if n == nil || n.Type() == core.None || n.Type() == core.Blocked {
+				continue
+			}
+			if _, ok := dist[n]; !ok {
+				dist[n] = dist[c] + 1
+				queue = append(queue, n)
+			}
+		}
+	}
+	return dist
+}
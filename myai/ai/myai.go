@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math/rand"
 	"net"
+	"strings"
 	"time"
 )
 
@@ -51,6 +52,9 @@ func goTo(conn *net.TCPConn, world *megagrid.WorldMap, targetCell *megagrid.Cell
 		return
 	}
 
+	// show the path in the simulator GUI
+	drawPath(myPosCell, conn)
+
 	// next step/cell in my path
 	nextCell := myPosCell.AParent
 	if nextCell == nil {
@@ -114,3 +118,16 @@ func setMove(acceleration *megagrid.Vector, conn *net.TCPConn) {
 	mov := fmt.Sprintf("%f|%f\n", acceleration.X, acceleration.Y)
 	_, _ = conn.Write([]byte(mov))
 }
+
+// drawPath sends the A* path (AParent chain) with the DRAW command.
+func drawPath(start *megagrid.Cell, conn *net.TCPConn) {
+	const cellSize = 40 // see core.CellSize
+	sb := new(strings.Builder)
+	for c, i := start, 0; c.AParent != nil && i < 500; c, i = c.AParent, i+1 {
+		n := c.AParent
+		sb.WriteString(fmt.Sprintf("DRAW|LINE|%d|%d|%d|%d\n",
+			c.XCol()*cellSize+cellSize/2, c.YRow()*cellSize+cellSize/2, n.XCol()*cellSize+cellSize/2, n.YRow()*cellSize+cellSize/2))
+	}
+	sb.WriteString("DRAW|SHOW\n")
+	_, _ = conn.Write([]byte(sb.String()))
+}
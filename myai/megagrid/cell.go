@@ -17,10 +17,26 @@ const (
 	Star    = 'x' // Star cell: increases the score on contact and then disappears (Tile)
 	Anti    = 'a' // Anti cell: reduce the score on contact and then disappears (Tile)
 	Spawn   = 'o' // Spawn cell: point where players are randomly placed
+
+	Shield   = 'h' // Shield cell: protects against the next bump and then disappears (Tile)
+	Mass     = 'm' // Mass cell: increases the ship mass for a while and then disappears (Tile)
+	Teleport = 't' // Teleport cell: moves the ship to the paired teleport cell
+	Crumble  = 'c' // Crumble cell: can be driven on, but turns into None after a ship has crossed it
+
+	ConveyorUp    = '^' // Conveyor cell: pushes the ship up
+	ConveyorDown  = 'v' // Conveyor cell: pushes the ship down
+	ConveyorLeft  = '<' // Conveyor cell: pushes the ship left
+	ConveyorRight = '>' // Conveyor cell: pushes the ship right
+
+	Hill = 'k' // Hill cell: zone of the king of the hill mode
+	Flag = 'f' // Flag cell: flag of the capture the flag mode
+	Goal = 'g' // Goal cell: base of the capture the flag mode
 )
 
 // CellTypes all supported cell types as slice
-var CellTypes = []byte{None, Blocked, Boost, Slow, Tile, Star, Anti, Spawn}
+var CellTypes = []byte{None, Blocked, Boost, Slow, Tile, Star, Anti, Spawn,
+	Shield, Mass, Teleport, Crumble, ConveyorUp, ConveyorDown, ConveyorLeft, ConveyorRight,
+	Hill, Flag, Goal}
 
 // CellSize is the dimension of the square cell
 const CellSize = 40.0 // cell image 40x40
@@ -121,6 +121,17 @@ func (mg MegaGrid) UpdateCosts() {
 			case Spawn: // Spawn cell: point where players are randomly placed
 				cost *= 1 // normal
 				break
+			case Shield, Mass, Hill, Flag, Goal: // power-ups and game mode markers
+				cost *= 1 // normal
+				break
+			case Crumble: // Crumble cell: turns into None after a ship has crossed it
+				cost *= 5 // bad
+				avoidCells = append(avoidCells, cell)
+				break
+			case Teleport, ConveyorUp, ConveyorDown, ConveyorLeft, ConveyorRight: // moves the ship
+				cost *= 7 // unpredictable
+				avoidCells = append(avoidCells, cell)
+				break
 			}
 
 			cell.cost = cost
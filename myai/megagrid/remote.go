@@ -90,7 +90,7 @@ func (w *WorldMap) parsePly(text string) {
 	for _, line := range strings.Split(text, "\n") {
 		// split elements
 		els := strings.Split(line, "|")
-		if len(els) == 10 {
+		if len(els) >= 10 {
 			for _, el := range els {
 				// split args
 				args := strings.Split(el, ":")
@@ -98,8 +98,8 @@ func (w *WorldMap) parsePly(text string) {
 					// process args
 					if args[0] == "PlayerID" {
 						currentPlayerID, _ = strconv.Atoi(strings.TrimSpace(args[1]))
-						if len(w.Players) <= currentPlayerID {
-							w.Players = append(w.Players, NewShip(w, currentPlayerID, "tmp", "red"))
+						for len(w.Players) <= currentPlayerID {
+							w.Players = append(w.Players, NewShip(w, len(w.Players), "tmp", "red"))
 						}
 					} else if args[0] == "Name" {
 						w.Players[currentPlayerID].Name = strings.TrimSpace(args[1])
@@ -123,6 +123,25 @@ func (w *WorldMap) parsePly(text string) {
 					} else if args[0] == "Score" {
 						s, _ := strconv.Atoi(strings.TrimSpace(args[1]))
 						w.Players[currentPlayerID].Score = s
+					} else if args[0] == "Shield" {
+						s, _ := strconv.Atoi(strings.TrimSpace(args[1]))
+						w.Players[currentPlayerID].Shield = s
+					} else if args[0] == "Mass" {
+						m, _ := strconv.ParseFloat(strings.TrimSpace(args[1]), 64)
+						w.Players[currentPlayerID].Mass = m
+					} else if args[0] == "Team" {
+						w.Players[currentPlayerID].Team = strings.TrimSpace(args[1])
+					} else if args[0] == "Class" {
+						w.Players[currentPlayerID].Class = strings.TrimSpace(args[1])
+					} else if args[0] == "Radius" {
+						r, _ := strconv.ParseFloat(strings.TrimSpace(args[1]), 64)
+						w.Players[currentPlayerID].Radius = r
+					} else if args[0] == "DashCooldown" {
+						c, _ := strconv.Atoi(strings.TrimSpace(args[1]))
+						w.Players[currentPlayerID].DashCooldown = c
+					} else if args[0] == "BrakeCooldown" {
+						c, _ := strconv.Atoi(strings.TrimSpace(args[1]))
+						w.Players[currentPlayerID].BrakeCooldown = c
 					}
 				}
 			}
@@ -6,10 +6,17 @@ type Ship struct {
 	Name     string
 	Color    string
 
-	Position     *Vector
-	Velocity     *Vector
-	Acceleration *Vector
-	Score        int
+	Position      *Vector
+	Velocity      *Vector
+	Acceleration  *Vector
+	Score         int
+	Shield        int
+	Mass          float64
+	Team          string
+	Class         string
+	Radius        float64
+	DashCooldown  int
+	BrakeCooldown int
 }
 
 func NewShip(world *WorldMap, playerID int, name, color string) *Ship {
@@ -23,6 +30,9 @@ func NewShip(world *WorldMap, playerID int, name, color string) *Ship {
 		Velocity:     new(Vector),
 		Acceleration: new(Vector),
 		Score:        100,
+		Mass:         1,
+		Class:        "normal",
+		Radius:       CellSize / 2,
 	}
 
 	return ship
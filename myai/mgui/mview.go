@@ -241,7 +241,9 @@ func drawGrid(screen *ebiten.Image, grid megagrid.MegaGrid, cost bool, xWidth, y
 			case megagrid.Slow:
 				screen.DrawImage(Games.Tile, op)
 				screen.DrawImage(Games.Slow, op)
-			case megagrid.Tile:
+			case megagrid.Tile, megagrid.Shield, megagrid.Mass, megagrid.Teleport, megagrid.Crumble,
+				megagrid.ConveyorUp, megagrid.ConveyorDown, megagrid.ConveyorLeft, megagrid.ConveyorRight,
+				megagrid.Hill, megagrid.Flag, megagrid.Goal:
 				screen.DrawImage(Games.Tile, op)
 			case megagrid.Star:
 				screen.DrawImage(Games.Tile, op)
@@ -3,7 +3,9 @@ package remote
 import (
 	"SpaceBumper/core"
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/textproto"
@@ -12,55 +14,139 @@ import (
 	"sync"
 )
 
-type server struct {
+// Server makes a game world available remotely.
+// The world can be replaced between two matches (see SetWorld).
+// (see NewServer)
+type Server struct {
 	host       string
 	port       string
 	world      *core.WorldMap
 	waitPlayer int
 
-	mux *sync.Mutex
+	listener net.Listener
+	mux      *sync.Mutex
 }
 
 // RunServer starts a server and makes the game world available remotely.
+// The world is frozen until waitPlayer players are registered.
+//
+// This call is blocking.
 func RunServer(host, port string, world *core.WorldMap, waitPlayer int) {
-
-	// Listen for incoming connections.
-	l, err := net.Listen("tcp", host+":"+port)
-	if err != nil {
+	ser := NewServer(host, port, waitPlayer)
+	ser.SetWorld(world)
+	if err := ser.Listen(); err != nil {
 		log.Fatalf("RunServer: %v\n", err)
 	}
+	ser.Serve()
+}
 
-	// Close the listener when the application closes.
-	defer func(l net.Listener) {
-		_ = l.Close()
-	}(l)
-
-	// Freeze world
-	world.Freeze(true) // undo in registerPlayer()
-
-	// server
-	ser := &server{
+// NewServer creates a server without world (see SetWorld and Start).
+func NewServer(host, port string, waitPlayer int) *Server {
+	return &Server{
 		host:       host,
 		port:       port,
-		world:      world,
 		waitPlayer: waitPlayer,
 		mux:        new(sync.Mutex),
 	}
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// Addr returns the server address '{host}:{port}'.
+func (ser *Server) Addr() string {
+	return ser.host + ":" + ser.port
+}
+
+// World returns the current world or nil.
+func (ser *Server) World() *core.WorldMap {
+	ser.mux.Lock()
+	defer ser.mux.Unlock()
+	return ser.world
+}
+
+//--------  Setter  --------------------------------------------------------------------------------------------------//
+
+// SetWorld replaces the world (e.g. a new match).
+// The connections of the remote players of the old world are closed.
+// The new world is frozen until waitPlayer players are registered
+// (players that are already registered, e.g. the local player, are counted).
+func (ser *Server) SetWorld(world *core.WorldMap) {
+	ser.mux.Lock()
+	defer ser.mux.Unlock()
+
+	// close old connections
+	if ser.world != nil && ser.world != world {
+		for _, p := range ser.world.Players() {
+			if c, ok := p.Remote().(io.Closer); ok {
+				_ = c.Close()
+			}
+		}
+	}
 
-	fmt.Println("START SERVER [" + host + ":" + port + "]")
+	// freeze world
+	ser.world = world
+	ser.world.Freeze(len(world.Players()) < ser.waitPlayer) // undo in handleRequest()
+}
+
+// SetWaitPlayer sets the number of players the next world waits for (see SetWorld).
+func (ser *Server) SetWaitPlayer(waitPlayer int) {
+	ser.mux.Lock()
+	defer ser.mux.Unlock()
+	ser.waitPlayer = waitPlayer
+}
+
+//--------  Run  -----------------------------------------------------------------------------------------------------//
+
+// Start listens and accepts connections in the background (see Listen and Serve).
+func (ser *Server) Start() error {
+	if err := ser.Listen(); err != nil {
+		return err
+	}
+	go ser.Serve()
+	return nil
+}
+
+// Listen opens the listener (see Serve).
+func (ser *Server) Listen() error {
+	l, err := net.Listen("tcp", ser.Addr())
+	if err != nil {
+		return err
+	}
+	ser.listener = l
+	fmt.Println("START SERVER [" + ser.Addr() + "]")
+	return nil
+}
+
+// Serve accepts incoming connections until the server is closed (see Close).
+//
+// This call is blocking.
+func (ser *Server) Serve() {
 	for {
 		// Listen for an incoming connection.
-		conn, err := l.Accept()
+		conn, err := ser.listener.Accept()
 		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return // closed
+			}
 			fmt.Println("Error accepting: ", err.Error())
+			continue
 		}
 		// Handle connections in a new goroutine.
 		go handleRequest(conn, ser)
 	}
 }
 
+// Close stops the server. Registered players keep their connection.
+func (ser *Server) Close() error {
+	if ser.listener == nil {
+		return nil
+	}
+	fmt.Println("STOP SERVER [" + ser.Addr() + "]")
+	return ser.listener.Close()
+}
+
 // Handles incoming requests.
-func handleRequest(conn net.Conn, ser *server) {
+func handleRequest(conn net.Conn, ser *Server) {
 	ser.mux.Lock()
 	defer ser.mux.Unlock()
 
@@ -81,19 +167,30 @@ func handleRequest(conn net.Conn, ser *server) {
 	var retMsg = ""
 
 	// extract command
-	// format:  "{pass}|{name}|{color}\n"
+	// format:  "{pass}|{name}|{color}\n", "{pass}|{name}|{color}|{team}\n" or "{pass}|{name}|{color}|{team}|{class}\n"
 	param := strings.Split(line, "|")
-	if len(param) != 3 {
-		retMsg = "ERROR: invalid command! use '{pass}|{name}|{color}\\n'"
+	if ser.world == nil {
+		retMsg = "ERROR: no match running"
+
+	} else if len(param) < 3 || len(param) > 5 {
+		retMsg = "ERROR: invalid command! use '{pass}|{name}|{color}\\n' or '{pass}|{name}|{color}|{team}|{class}\\n'"
 
 	} else {
-		// extract name and color
+		// extract name, color, team (optional) and class (optional)
 		name := param[1]
 		color := param[2]
-		fmt.Printf("request: name=%s, color=%s\n", name, color)
+		team := ""
+		if len(param) >= 4 {
+			team = param[3]
+		}
+		class := ""
+		if len(param) == 5 {
+			class = param[4]
+		}
+		fmt.Printf("request: name=%s, color=%s, team=%s, class=%s\n", name, color, team, class)
 
 		// add player
-		id, err := ser.world.AddPlayer(name, color, conn)
+		id, err := ser.world.AddPlayer(name, color, team, class, conn)
 		if err != nil {
 			retMsg = err.Error()
 		} else {
@@ -108,7 +205,7 @@ func handleRequest(conn net.Conn, ser *server) {
 	}
 
 	// un-freeze
-	if ser.waitPlayer <= len(ser.world.Players()) {
+	if ser.world != nil && ser.waitPlayer <= len(ser.world.Players()) {
 		ser.world.Freeze(false)
 	}
 }
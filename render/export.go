@@ -0,0 +1,186 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// maxGIFFrames limits the frames of an animated GIF (memory, all frames are kept until Close)
+const maxGIFFrames = 600
+
+// Exporter writes the rendered frames (see NewExporter).
+type Exporter interface {
+	// Add adds a rendered frame.
+	Add(img *image.RGBA) error
+	// Close writes the remaining output.
+	Close() error
+}
+
+// NewExporter returns the exporter of the output path (by file extension):
+//
+//	.png   snapshot of the last frame
+//	.gif   animated GIF (max. 600 frames, fps is the playback speed)
+//	other  directory with one PNG per frame (frame_000000.png, ...), e.g. for ffmpeg
+func NewExporter(out string, fps float64) (Exporter, error) {
+	if fps <= 0 {
+		fps = 10
+	}
+	switch strings.ToLower(filepath.Ext(out)) {
+	case ".png":
+		return &snapshot{path: out}, nil
+	case ".gif":
+		delay := int(100/fps + 0.5)
+		if delay < 2 {
+			delay = 2 // most viewers ignore smaller delays
+		}
+		return &animation{path: out, delay: delay}, nil
+	default:
+		if err := os.MkdirAll(out, 0755); err != nil {
+			return nil, err
+		}
+		return &sequence{dir: out}, nil
+	}
+}
+
+//--------  Snapshot  ------------------------------------------------------------------------------------------------//
+
+// snapshot writes the last frame as PNG.
+type snapshot struct {
+	path string
+	last *image.RGBA
+}
+
+func (s *snapshot) Add(img *image.RGBA) error {
+	s.last = img
+	return nil
+}
+
+func (s *snapshot) Close() error {
+	if s.last == nil {
+		return fmt.Errorf("snapshot %s: no frame", s.path)
+	}
+	return writePNG(s.path, s.last)
+}
+
+//--------  Animation  -----------------------------------------------------------------------------------------------//
+
+// animation collects the frames of an animated GIF (Plan 9 palette with ordered dithering, see quantize).
+type animation struct {
+	path    string
+	delay   int // 1/100 s per frame
+	gif     gif.GIF
+	skipped int // frames after maxGIFFrames
+}
+
+func (a *animation) Add(img *image.RGBA) error {
+	if len(a.gif.Image) >= maxGIFFrames {
+		a.skipped++
+		return nil
+	}
+	a.gif.Image = append(a.gif.Image, quantize(img))
+	a.gif.Delay = append(a.gif.Delay, a.delay)
+	return nil
+}
+
+func (a *animation) Close() error {
+	if len(a.gif.Image) == 0 {
+		return fmt.Errorf("gif %s: no frame", a.path)
+	}
+	if a.skipped > 0 {
+		fmt.Printf("WARNING: gif %s: %d frames skipped (max. %d frames, use a frame directory or -render-every)\n", a.path, a.skipped, maxGIFFrames)
+	}
+	file, err := os.Create(a.path)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(file, &a.gif); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+//--------  Sequence  ------------------------------------------------------------------------------------------------//
+
+// sequence writes each frame as PNG into a directory.
+type sequence struct {
+	dir string
+	n   int
+}
+
+func (s *sequence) Add(img *image.RGBA) error {
+	path := filepath.Join(s.dir, fmt.Sprintf("frame_%06d.png", s.n))
+	s.n++
+	return writePNG(path, img)
+}
+
+func (s *sequence) Close() error {
+	return nil
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+var (
+	plan9LUT  []uint8 // palette index per 15 bit color (see quantize)
+	plan9Once sync.Once
+)
+
+// bayer is the 4x4 ordered dithering matrix
+var bayer = [4][4]int{{0, 8, 2, 10}, {12, 4, 14, 6}, {3, 11, 1, 9}, {15, 7, 13, 5}}
+
+// quantize converts the opaque image to the Plan 9 palette.
+// A lookup table with ordered dithering is much faster than Floyd-Steinberg (no slow down of the game).
+func quantize(img *image.RGBA) *image.Paletted {
+	plan9Once.Do(func() {
+		plan9LUT = make([]uint8, 1<<15)
+		for i := range plan9LUT {
+			c := color.RGBA{R: uint8(i>>10) << 3, G: uint8(i>>5&31) << 3, B: uint8(i&31) << 3, A: 0xff}
+			plan9LUT[i] = uint8(color.Palette(palette.Plan9).Index(c))
+		}
+	})
+
+	b := img.Bounds()
+	p := image.NewPaletted(b, palette.Plan9)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			o := img.PixOffset(x, y)
+			d := (bayer[y&3][x&3] - 8) * 2
+			r, g, bl := dither(img.Pix[o], d), dither(img.Pix[o+1], d), dither(img.Pix[o+2], d)
+			p.Pix[p.PixOffset(x, y)] = plan9LUT[int(r>>3)<<10|int(g>>3)<<5|int(bl>>3)]
+		}
+	}
+	return p
+}
+
+// dither adds the offset to the color value (clamped).
+func dither(v uint8, d int) uint8 {
+	n := int(v) + d
+	if n < 0 {
+		return 0
+	}
+	if n > 255 {
+		return 255
+	}
+	return uint8(n)
+}
+
+// writePNG writes the image as PNG file.
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, img); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,112 @@
+package render
+
+import (
+	"SpaceBumper/core"
+	"strings"
+)
+
+// Frame is the state of the world at one iteration (everything the renderer needs).
+// Frames are captured from a live world (see Capture) or read from a replay (see ReadReplay).
+type Frame struct {
+	Iteration uint64      `json:"iteration"`
+	Endtime   uint64      `json:"endtime"`
+	Map       string      `json:"map,omitempty"`   // map name
+	Cells     []string    `json:"cells,omitempty"` // one string per row (cell types), omitted in the replay if unchanged
+	Ships     []ShipState `json:"ships"`
+	Overtime  bool        `json:"overtime,omitempty"` // see core.TieBreak
+	GameOver  bool        `json:"gameOver,omitempty"`
+	Winner    string      `json:"winner,omitempty"` // winner name or 'team {name}' (empty: no winner)
+}
+
+// ShipState is the state of a ship in a frame.
+type ShipState struct {
+	Name   string  `json:"name"`
+	Color  string  `json:"color"`
+	Team   string  `json:"team,omitempty"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Angle  float64 `json:"angle"`
+	Radius float64 `json:"radius"`
+	Scale  float64 `json:"scale"` // mass power-up scale (1 = normal, see core.Mass)
+	Score  int     `json:"score"`
+	Stars  int     `json:"stars"`
+	Shield bool    `json:"shield,omitempty"`
+	Flag   bool    `json:"flag,omitempty"` // carries a flag (see core.CaptureTheFlag)
+	Alive  bool    `json:"alive"`
+}
+
+// Capture returns the current frame of the world.
+// Call it from the update goroutine (e.g. core.WorldMap.OnUpdate) to get a consistent state.
+func Capture(world *core.WorldMap) Frame {
+	iteration, endtime, _ := world.Stats()
+	f := Frame{
+		Iteration: iteration,
+		Endtime:   endtime,
+		Map:       world.Info().Name,
+		Overtime:  world.Overtime(),
+		GameOver:  world.GameOver(),
+	}
+
+	// cells
+	for yRow := 0; yRow < world.YHeight(); yRow++ {
+		sb := new(strings.Builder)
+		for xCol := 0; xCol < world.XWidth(); xCol++ {
+			sb.WriteByte(world.Cell(xCol, yRow).Type())
+		}
+		f.Cells = append(f.Cells, sb.String())
+	}
+
+	// ships
+	ctf, _ := world.GameMode().(*core.CaptureTheFlag)
+	for _, s := range world.Players() {
+		pos := s.Position()
+		f.Ships = append(f.Ships, ShipState{
+			Name:   s.Name(),
+			Color:  s.Color(),
+			Team:   s.Team(),
+			X:      pos.X(),
+			Y:      pos.Y(),
+			Angle:  s.Angle(),
+			Radius: s.Radius(),
+			Scale:  s.Mass()/s.Class().Mass/2 + 0.5,
+			Score:  s.Score(),
+			Stars:  s.Stars(),
+			Shield: s.Shield() > 0,
+			Flag:   ctf != nil && ctf.Carrying(s),
+			Alive:  s.IsAlive(),
+		})
+	}
+
+	// result
+	if f.GameOver {
+		if w := world.Winner(); w != nil && w.Team() != "" {
+			f.Winner = "team " + w.Team()
+		} else if w != nil {
+			f.Winner = w.Name()
+		}
+	}
+	return f
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// Width returns the map width (cells).
+func (f Frame) Width() int {
+	if len(f.Cells) == 0 {
+		return 0
+	}
+	return len(f.Cells[0])
+}
+
+// Height returns the map height (cells).
+func (f Frame) Height() int {
+	return len(f.Cells)
+}
+
+// Cell returns the cell type (core.None outside of the map).
+func (f Frame) Cell(xCol, yRow int) byte {
+	if yRow < 0 || yRow >= len(f.Cells) || xCol < 0 || xCol >= len(f.Cells[yRow]) {
+		return core.None
+	}
+	return f.Cells[yRow][xCol]
+}
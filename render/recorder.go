@@ -0,0 +1,170 @@
+package render
+
+import (
+	"SpaceBumper/core"
+	"errors"
+	"sync"
+)
+
+// Options are the settings of a Recorder.
+type Options struct {
+	Scale float64 // image scale (default 0.5 = 20 pixels per cell)
+	Every uint64  // export every n-th iteration (default 6 = 10 frames per second)
+	From  uint64  // first exported iteration
+	To    uint64  // last exported iteration (0 = until the game is over)
+}
+
+// Recorder records the frames of a game to a replay file and exports the rendered frames (see NewExporter).
+// The frames are rendered in the background, the game only captures the state (see Attach).
+type Recorder struct {
+	opts     Options
+	replay   *ReplayWriter // optional
+	exporter Exporter      // optional
+	renderer *Renderer
+
+	frames chan Frame
+	done   chan struct{}
+	err    error // first error of the background worker
+
+	mux    sync.Mutex
+	closed bool
+}
+
+// NewRecorder creates a recorder that writes the replay and/or exports the rendered frames.
+// An empty path disables the replay or the export.
+func NewRecorder(replayPath, out string, o Options) (*Recorder, error) {
+	if replayPath == "" && out == "" {
+		return nil, errors.New("recorder needs a replay file or an output")
+	}
+	if o.Scale <= 0 {
+		o.Scale = 0.5
+	}
+	if o.Every == 0 {
+		o.Every = 6
+	}
+
+	r := &Recorder{
+		opts:     o,
+		renderer: NewRenderer(o.Scale),
+		frames:   make(chan Frame, 64),
+		done:     make(chan struct{}),
+	}
+	if replayPath != "" {
+		w, err := NewReplayWriter(replayPath)
+		if err != nil {
+			return nil, err
+		}
+		r.replay = w
+	}
+	if out != "" {
+		e, err := NewExporter(out, float64(core.TPS)/float64(o.Every))
+		if err != nil {
+			if r.replay != nil {
+				_ = r.replay.Close()
+			}
+			return nil, err
+		}
+		r.exporter = e
+	}
+	go r.run()
+	return r, nil
+}
+
+// RenderReplay exports the rendered frames of a replay file (see NewExporter).
+func RenderReplay(replayPath, out string, o Options) error {
+	r, err := NewRecorder("", out, o)
+	if err != nil {
+		return err
+	}
+	if err := ReadReplay(replayPath, r.Capture); err != nil {
+		_ = r.Close()
+		return err
+	}
+	return r.Close()
+}
+
+//--------  Update  --------------------------------------------------------------------------------------------------//
+
+// Attach records the world from now on: the current frame and the frame of each update.
+// The recording stops when the game is over (without waiting for the background worker),
+// call Close to wait for the written files (also if the game is stopped before).
+func (r *Recorder) Attach(world *core.WorldMap) {
+	_ = r.Capture(Capture(world))
+	world.OnUpdate(func(m *core.WorldMap) {
+		_ = r.Capture(Capture(m))
+		if m.GameOver() {
+			r.stop()
+		}
+	})
+}
+
+// Capture records the frame.
+// No frame is dropped: if the background worker is 64 frames behind, Capture blocks until
+// a frame is written (the game slows down, e.g. with a slow export, see Options.Every).
+// Returns an error if the recorder is closed.
+func (r *Recorder) Capture(f Frame) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.closed {
+		return errors.New("recorder closed")
+	}
+	r.frames <- f
+	return nil
+}
+
+// Close waits for the background worker and closes the replay and the export.
+// Returns the first error of the recording. Close can be called several times.
+func (r *Recorder) Close() error {
+	r.stop()
+	<-r.done
+	return r.err
+}
+
+// run writes and exports the captured frames (background worker).
+func (r *Recorder) run() {
+	defer close(r.done)
+	for f := range r.frames {
+		if r.err != nil {
+			continue // drain
+		}
+		if r.replay != nil {
+			r.fail(r.replay.Write(f))
+		}
+		if r.exporter != nil && r.export(f) {
+			r.fail(r.exporter.Add(r.renderer.Render(f)))
+		}
+	}
+	if r.replay != nil {
+		r.fail(r.replay.Close())
+	}
+	if r.exporter != nil {
+		r.fail(r.exporter.Close())
+	}
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+// export returns true if the frame is in the exported range (the last frame of a game is always exported).
+func (r *Recorder) export(f Frame) bool {
+	if f.Iteration < r.opts.From || (r.opts.To > 0 && f.Iteration > r.opts.To) {
+		return false
+	}
+	return f.GameOver || (f.Iteration-r.opts.From)%r.opts.Every == 0
+}
+
+// stop ends the recording: the background worker writes the remaining frames and closes the files (see Close).
+func (r *Recorder) stop() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if !r.closed {
+		r.closed = true
+		close(r.frames)
+	}
+}
+
+// fail keeps the first error.
+func (r *Recorder) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
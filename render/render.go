@@ -0,0 +1,393 @@
+// Package render draws frames of the world into images without an ebiten window (headless).
+// It uses the same sprites as the GUI (see sprites.Games) and exports snapshots, animated GIFs
+// or frame sequences of a live game or a replay (see Recorder and ReadReplay).
+package render
+
+import (
+	"SpaceBumper/core"
+	"SpaceBumper/gui/resources/sprites"
+	"fmt"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/f64"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+var (
+	textColor   = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	panelColor  = color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0xa0}
+	shieldAlpha = 0.4 // shield overlay opacity (like the GUI)
+)
+
+// Renderer draws frames into RGBA images.
+// The map layer (background and cells) is cached until the cells change.
+// A Renderer is not safe for concurrent use.
+type Renderer struct {
+	scale float64 // image pixels per world pixel
+
+	cells []string    // cells of the cached layer
+	layer *image.RGBA // background and cells
+	tints map[string]*image.RGBA
+}
+
+// NewRenderer creates a renderer with the image scale (1 = 40 pixels per cell).
+func NewRenderer(scale float64) *Renderer {
+	if scale <= 0 {
+		scale = 1
+	}
+	return &Renderer{scale: scale, tints: make(map[string]*image.RGBA)}
+}
+
+//--------  Getter  --------------------------------------------------------------------------------------------------//
+
+// Scale returns the image pixels per world pixel.
+func (r *Renderer) Scale() float64 {
+	return r.scale
+}
+
+//--------  Draw  ----------------------------------------------------------------------------------------------------//
+
+// Render draws the frame into a new image.
+// Dead ships are not drawn, the names and the scoreboard are not scaled.
+func (r *Renderer) Render(f Frame) *image.RGBA {
+	r.updateLayer(f)
+	img := image.NewRGBA(r.layer.Bounds())
+	copy(img.Pix, r.layer.Pix)
+
+	for _, s := range f.Ships {
+		if s.Alive {
+			r.drawShip(img, s)
+		}
+	}
+	for _, s := range f.Ships {
+		if s.Alive {
+			r.drawLabels(img, s)
+		}
+	}
+	drawPanel(img, f)
+	if f.GameOver {
+		drawBanner(img, f)
+	}
+	return img
+}
+
+// updateLayer draws the background and the cells if the cells changed.
+func (r *Renderer) updateLayer(f Frame) {
+	if r.layer != nil && equal(r.cells, f.Cells) {
+		return
+	}
+	r.cells = f.Cells
+
+	w := int(math.Ceil(float64(f.Width()) * core.CellSize * r.scale))
+	h := int(math.Ceil(float64(f.Height()) * core.CellSize * r.scale))
+	r.layer = image.NewRGBA(image.Rect(0, 0, w, h))
+
+	// background (stretched like the GUI)
+	draw.ApproxBiLinear.Scale(r.layer, r.layer.Bounds(), sprites.Games.Bg, sprites.Games.Bg.Bounds(), draw.Src, nil)
+
+	// cells (integer bounds, no gaps between the cells)
+	for yRow := 0; yRow < f.Height(); yRow++ {
+		for xCol := 0; xCol < f.Width(); xCol++ {
+			t := f.Cell(xCol, yRow)
+			if t == core.None {
+				continue // background
+			}
+			x0, y0 := float64(xCol)*core.CellSize, float64(yRow)*core.CellSize
+			rect := image.Rect(r.px(x0), r.px(y0), r.px(x0+core.CellSize), r.px(y0+core.CellSize))
+			img := cellImage(t)
+			if img == sprites.Games.Error {
+				draw.BiLinear.Scale(r.layer, rect, img, img.Bounds(), draw.Over, nil)
+				continue
+			}
+			draw.BiLinear.Scale(r.layer, rect, sprites.Games.Tile, sprites.Games.Tile.Bounds(), draw.Over, nil)
+			if img == nil {
+				continue // ground only
+			}
+			if img == sprites.Games.Conveyor {
+				img = rotate(img, conveyorAngle(t))
+			}
+			draw.BiLinear.Scale(r.layer, rect, img, img.Bounds(), draw.Over, nil)
+		}
+	}
+}
+
+// drawShip draws the ship with its paint, team marker, shield and carried flag.
+func (r *Renderer) drawShip(dst *image.RGBA, s ShipState) {
+	x, y := s.X*r.scale, s.Y*r.scale
+
+	// ship (scaled to its radius and mass, the image points up)
+	scale := s.Radius / core.CellRadius * s.Scale * r.scale
+	img := shipImage(s.Color)
+	r.drawSprite(dst, img, x, y, scale, s.Angle+4.71239)
+	if img == sprites.Games.Ship {
+		r.drawSprite(dst, r.tint("paint:"+s.Color, sprites.Games.Paint, shipRGB(s.Color), 1), x, y, scale, s.Angle+4.71239)
+	}
+
+	// team marker (see core.Ship.Team)
+	if s.Team != "" {
+		r.drawSprite(dst, r.tint("team:"+s.Team, sprites.Games.Ring, core.TeamColor(s.Team), 1), x, y, 1.2*r.scale, 0)
+	}
+
+	// shield (see core.Shield)
+	if s.Shield {
+		white := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+		r.drawSprite(dst, r.tint("shield", sprites.Games.Shield, white, shieldAlpha), x, y, 1.5*r.scale, 0)
+	}
+
+	// carried flag (top left corner at the ship, one cell up)
+	if s.Flag {
+		half := core.CellRadius * 0.6 * r.scale
+		r.drawSprite(dst, sprites.Games.Flag, x+half, y-core.CellSize*r.scale+half, 0.6*r.scale, 0)
+	}
+}
+
+// drawLabels draws the name (below) and the score (center) of the ship.
+func (r *Renderer) drawLabels(dst *image.RGBA, s ShipState) {
+	x, y := s.X*r.scale, s.Y*r.scale
+	name := s.Name
+	if s.Team != "" {
+		name = fmt.Sprintf("%s [%s]", s.Name, s.Team)
+	}
+	drawText(dst, name, int(x)-textWidth(name)/2, int(y+5+core.CellRadius*r.scale)+lineHeight, textColor)
+	score := fmt.Sprintf("%d", s.Score)
+	drawText(dst, score, int(x)-textWidth(score)/2, int(y)+lineHeight/2-2, textColor)
+}
+
+// drawSprite draws the image centered at x, y (image pixels), scaled and rotated around its center.
+func (r *Renderer) drawSprite(dst *image.RGBA, src image.Image, x, y, scale, angle float64) {
+	b := src.Bounds()
+	w, h := float64(b.Dx()), float64(b.Dy())
+	sin, cos := math.Sincos(angle)
+	m := f64.Aff3{
+		scale * cos, -scale * sin, x - scale*(cos*w/2-sin*h/2),
+		scale * sin, scale * cos, y - scale*(sin*w/2+cos*h/2),
+	}
+	draw.BiLinear.Transform(dst, m, src, b, draw.Over, nil)
+}
+
+// tint returns the cached image with the multiplied color and alpha (like ebiten's ColorM.Scale).
+func (r *Renderer) tint(key string, src image.Image, c color.RGBA, alpha float64) *image.RGBA {
+	if img, ok := r.tints[key]; ok {
+		return img
+	}
+	b := src.Bounds()
+	img := image.NewRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			sr, sg, sb, sa := src.At(x, y).RGBA() // premultiplied
+			img.SetRGBA(x, y, color.RGBA{
+				R: uint8(float64(sr>>8) * float64(c.R) / 255 * alpha),
+				G: uint8(float64(sg>>8) * float64(c.G) / 255 * alpha),
+				B: uint8(float64(sb>>8) * float64(c.B) / 255 * alpha),
+				A: uint8(float64(sa>>8) * alpha),
+			})
+		}
+	}
+	r.tints[key] = img
+	return img
+}
+
+// px converts world pixels to image pixels.
+func (r *Renderer) px(v float64) int {
+	return int(math.Round(v * r.scale))
+}
+
+// drawPanel draws the map name, the time and the scoreboard (top left).
+func drawPanel(dst *image.RGBA, f Frame) {
+	lines := []string{fmt.Sprintf("%s  %s", f.Map, formatTime(f))}
+	ships := make([]ShipState, len(f.Ships))
+	copy(ships, f.Ships)
+	sort.SliceStable(ships, func(i, j int) bool { return ships[i].Score > ships[j].Score })
+	for i, s := range ships {
+		state := ""
+		if !s.Alive {
+			state = " dead"
+		}
+		lines = append(lines, fmt.Sprintf("%d. %-12.12s %5d  *%d%s", i+1, s.Name, s.Score, s.Stars, state))
+	}
+
+	width := 0
+	for _, l := range lines {
+		if w := textWidth(l); w > width {
+			width = w
+		}
+	}
+	panel := image.Rect(4, 4, 4+width+8, 4+len(lines)*lineHeight+8)
+	draw.Draw(dst, panel, image.NewUniform(panelColor), image.Point{}, draw.Over)
+	for i, l := range lines {
+		clr := textColor
+		if i > 0 {
+			clr = shipRGB(ships[i-1].Color)
+		}
+		drawText(dst, l, 8, 8+(i+1)*lineHeight-3, clr)
+	}
+}
+
+// drawBanner draws the game over message (center).
+func drawBanner(dst *image.RGBA, f Frame) {
+	msg := "GAME OVER: no winner"
+	if f.Winner != "" {
+		msg = fmt.Sprintf("GAME OVER: %s wins!", f.Winner)
+	}
+	b := dst.Bounds()
+	w := textWidth(msg)
+	x, y := (b.Dx()-w)/2, b.Dy()/2
+	draw.Draw(dst, image.Rect(x-8, y-lineHeight-4, x+w+8, y+8), image.NewUniform(panelColor), image.Point{}, draw.Over)
+	drawText(dst, msg, x, y, textColor)
+}
+
+//--------  Helper  --------------------------------------------------------------------------------------------------//
+
+// face is the font of all texts (fixed size, no font files needed)
+var face = basicfont.Face7x13
+
+// lineHeight is the text line height (pixels)
+const lineHeight = 13
+
+// drawText draws the text with its baseline at y.
+func drawText(dst *image.RGBA, s string, x, y int, clr color.Color) {
+	d := font.Drawer{Dst: dst, Src: image.NewUniform(clr), Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(s)
+}
+
+// textWidth returns the width of the text (pixels).
+func textWidth(s string) int {
+	return font.MeasureString(face, s).Round()
+}
+
+// formatTime returns the remaining time (mm:ss), OVERTIME or the elapsed time without end.
+func formatTime(f Frame) string {
+	switch {
+	case f.Overtime:
+		return "OVERTIME"
+	case f.Endtime != math.MaxUint64 && f.Endtime > f.Iteration:
+		return formatTicks(f.Endtime - f.Iteration)
+	case f.Endtime != math.MaxUint64:
+		return formatTicks(0)
+	default:
+		return formatTicks(f.Iteration)
+	}
+}
+
+// formatTicks formats the ticks as minutes and seconds (see core.TPS).
+func formatTicks(ticks uint64) string {
+	seconds := (ticks + core.TPS - 1) / core.TPS // round up
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// shipRGB returns the RGB value of the ship color (white if invalid).
+func shipRGB(c string) color.RGBA {
+	rgb, err := core.ParseColor(c)
+	if err != nil {
+		return textColor
+	}
+	return rgb
+}
+
+// shipImage returns the image of the ship color.
+// Hex colors use the neutral ship that is drawn with the tinted paint layer (see core.ParseColor).
+func shipImage(c string) image.Image {
+	switch c {
+	case "red":
+		return sprites.Games.Red
+	case "blue":
+		return sprites.Games.Blue
+	case "green":
+		return sprites.Games.Green
+	case "orange":
+		return sprites.Games.Orange
+	default:
+		return sprites.Games.Ship
+	}
+}
+
+// cellImage returns the image of the cell type that is drawn on the ground (Tile).
+// The void (None) and the ground return nil, unknown types the error image.
+func cellImage(t byte) image.Image {
+	switch t {
+	case core.None, core.Tile:
+		return nil
+	case core.Blocked:
+		return sprites.Games.Block
+	case core.Boost:
+		return sprites.Games.Boost
+	case core.Slow:
+		return sprites.Games.Slow
+	case core.Star:
+		return sprites.Games.Star
+	case core.Anti:
+		return sprites.Games.Anti
+	case core.Spawn:
+		return sprites.Games.Spawn
+	case core.Shield:
+		return sprites.Games.Shield
+	case core.Mass:
+		return sprites.Games.Mass
+	case core.Teleport:
+		return sprites.Games.Teleport
+	case core.Crumble:
+		return sprites.Games.Crumble
+	case core.ConveyorUp, core.ConveyorDown, core.ConveyorLeft, core.ConveyorRight:
+		return sprites.Games.Conveyor
+	case core.Hill:
+		return sprites.Games.Hill
+	case core.Flag:
+		return sprites.Games.Flag
+	case core.Goal:
+		return sprites.Games.Goal
+	default:
+		return sprites.Games.Error
+	}
+}
+
+// conveyorAngle returns the quarter turns of the conveyor image (points right).
+func conveyorAngle(t byte) int {
+	switch t {
+	case core.ConveyorDown:
+		return 1
+	case core.ConveyorLeft:
+		return 2
+	case core.ConveyorUp:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// rotate returns the square image rotated clockwise by quarter turns (exact, no filtering).
+func rotate(src image.Image, quarters int) image.Image {
+	if quarters%4 == 0 {
+		return src
+	}
+	b := src.Bounds()
+	n := b.Dx()
+	img := image.NewRGBA(image.Rect(0, 0, n, n))
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			rx, ry := x, y
+			for i := 0; i < quarters%4; i++ {
+				rx, ry = n-1-ry, rx
+			}
+			img.Set(rx, ry, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return img
+}
+
+// equal returns true if both cell lists are the same.
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
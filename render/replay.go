@@ -0,0 +1,81 @@
+package render
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ReplayWriter writes frames to a replay file (gzip compressed JSON, one frame per line).
+// The cells are only written if they changed since the previous frame (see ReadReplay).
+type ReplayWriter struct {
+	file  *os.File
+	gz    *gzip.Writer
+	enc   *json.Encoder
+	cells []string // cells of the previous frame
+}
+
+// NewReplayWriter creates the replay file.
+func NewReplayWriter(path string) (*ReplayWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	gz := gzip.NewWriter(file)
+	return &ReplayWriter{file: file, gz: gz, enc: json.NewEncoder(gz)}, nil
+}
+
+// Write appends the frame to the replay.
+func (w *ReplayWriter) Write(f Frame) error {
+	if equal(w.cells, f.Cells) {
+		f.Cells = nil // unchanged
+	} else {
+		w.cells = f.Cells
+	}
+	return w.enc.Encode(f)
+}
+
+// Close flushes and closes the replay file.
+func (w *ReplayWriter) Close() error {
+	if err := w.gz.Close(); err != nil {
+		_ = w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// ReadReplay reads all frames of a replay file and calls fn for each frame (in order).
+// Frames without cells get the cells of the previous frame. The reading stops at the first error of fn.
+func ReadReplay(path string, fn func(f Frame) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return fmt.Errorf("replay %s: %v", path, err)
+	}
+	defer gz.Close()
+
+	dec := json.NewDecoder(gz)
+	var cells []string
+	for n := 1; ; n++ {
+		var f Frame
+		if err := dec.Decode(&f); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("replay %s: frame %d: %v", path, n, err)
+		}
+		if f.Cells == nil {
+			f.Cells = cells
+		}
+		cells = f.Cells
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+}